@@ -3,134 +3,467 @@ package gofofa
 import (
 	"encoding/csv"
 	"fmt"
+	"github.com/FofaInfo/GoFOFA/pkg/outformats"
 	"github.com/FofaInfo/GoFOFA/pkg/readformats"
 	"github.com/expr-lang/expr"
-	"log"
+	"github.com/expr-lang/expr/vm"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 )
 
+const (
+	// CategoryTagField default field of matched categories in tagging mode
+	CategoryTagField = "category"
+	// CategoryTagSeparator default separator of matched categories in tagging mode
+	CategoryTagSeparator = ","
+)
+
 type CategoryOptions struct {
+	Categories   []Cate // categories used instead of those of config file if not empty, like those of LoadConfigs
 	Unique       bool   // is the classification unique
-	RelationFile string // relation file
-	SourceField  string // source field
-	TargetField  string // target field
+	RelationFile string // relation file of SourceField and TargetField, like ip and owner, joined into each row
+	SourceField  string // field of input joined with the same field of relation file
+	TargetField  string // field of relation file written into each row, appended if not exists in input
+	Format       string // format of input file, detected by extension or content if empty
+	OutDir       string // dir of one csv file per category, result/<timestamp> under current dir if empty
+
+	// NewWriter enables tagging mode: all rows are written to one writer with a field of matched categories,
+	// instead of one file per category. It is called once with fields of input and the tag field,
+	// the header is written and the writer is closed by Category.
+	NewWriter    func(fields []string) (outformats.OutWriter, error)
+	TagField     string // field of matched categories in tagging mode, CategoryTagField if empty
+	TagSeparator string // separator of matched categories in tagging mode, CategoryTagSeparator if empty
+
+	OnSkip func(file string, err *readformats.RowError) // called with each row of input and relation file which is skipped
 }
 
-func evaluateExpressions(filters []string, data readformats.CSVRow) (bool, error) {
-	// 处理数据
-	env := make(map[string]interface{})
-	for i, value := range data {
-		env[i] = value
-	}
+// CategoryCount matched rows of a category
+type CategoryCount struct {
+	Name   string `json:"name"`
+	Count  int    `json:"count"`
+	Errors int    `json:"errors,omitempty"` // rows failed to evaluate
+	File   string `json:"file,omitempty"`   // output file, empty in tagging mode
+}
 
-	// 添加过滤器内置方法
-	env["CONTAIN"] = strings.Contains
+// CategorySummary summary of Category
+type CategorySummary struct {
+	Dir        string          `json:"dir,omitempty"`     // output dir, empty in tagging mode
+	Total      int             `json:"total"`             // rows of input
+	Matched    int             `json:"matched"`           // rows matched at least one category
+	Skipped    int             `json:"skipped,omitempty"` // rows of input skipped, which can't be aligned with the header
+	Categories []CategoryCount `json:"categories"`        // in order of config
+}
 
-	for _, filter := range filters {
-		program, err := expr.Compile(filter, expr.Env(env))
-		if err != nil {
-			return false, err
+// CategoryError error of compiling a filter of a category
+type CategoryError struct {
+	Name   string // name of category
+	Index  int    // index of filter in category
+	Filter string
+	Err    error
+}
+
+func (e *CategoryError) Error() string {
+	return fmt.Sprintf("category %s filter %d: %v", e.Name, e.Index, e.Err)
+}
+
+func (e *CategoryError) Unwrap() error {
+	return e.Err
+}
+
+// categoryRule filters of a category compiled once
+type categoryRule struct {
+	name     string
+	programs []*vm.Program
+}
+
+// compileCategories compile filters of categories against fields, filters should return bool,
+// returns the first *CategoryError if any filter is broken
+func compileCategories(categories []Cate, fields []string) ([]categoryRule, error) {
+	rules, errs := compileAllCategories(categories, fields)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return rules, nil
+}
+
+// compileAllCategories compile filters of categories against fields, returns errors of all broken filters
+func compileAllCategories(categories []Cate, fields []string) ([]categoryRule, []*CategoryError) {
+	env := exprEnv(fields)
+	var rules []categoryRule
+	var errs []*CategoryError
+	for _, cate := range categories {
+		rule := categoryRule{name: cate.Name}
+		for i, filter := range cate.Filters {
+			program, err := compileExpr(filter, env)
+			if err != nil {
+				errs = append(errs, &CategoryError{Name: cate.Name, Index: i, Filter: filter, Err: err})
+				continue
+			}
+			rule.programs = append(rule.programs, program)
 		}
+		rules = append(rules, rule)
+	}
+	return rules, errs
+}
 
+// match index of the first filter of rule matches env, -1 if not matched
+func (r *categoryRule) match(env map[string]interface{}) (int, error) {
+	for i, program := range r.programs {
 		output, err := expr.Run(program, env)
 		if err != nil {
-			return false, err
+			return -1, err
 		}
-
 		if output.(bool) {
-			return true, nil
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// categoryFields fields appended with variables referred by filters of categories
+func categoryFields(categories []Cate, fields []string) []string {
+	fields = slices.Clip(fields)
+	for _, cate := range categories {
+		for _, filter := range cate.Filters {
+			variables, err := exprVariables(filter)
+			if err != nil {
+				// reported by compiling
+				continue
+			}
+			for _, v := range variables {
+				if !slices.Contains(fields, v) {
+					fields = append(fields, v)
+				}
+			}
 		}
 	}
-	return false, nil
+	return fields
+}
+
+// CheckCategories compile all filters of categories against fields, like those of a sample file,
+// or any field referred by filters if fields is nil,
+// returns errors of broken filters with category name and filter index, nil if all are valid
+func CheckCategories(categories []Cate, fields []string) []*CategoryError {
+	if fields == nil {
+		fields = categoryFields(categories, nil)
+	}
+	_, errs := compileAllCategories(categories, fields)
+	return errs
 }
 
-func Category(configFile, inputFile string, options ...CategoryOptions) (string, error) {
-	var (
-		unique bool
-	)
+// CategoryMatch result of a category of a row
+type CategoryMatch struct {
+	Name   string `json:"name"`
+	Match  bool   `json:"match"`
+	Filter int    `json:"filter"`          // index of the first matched filter, -1 if not matched
+	Error  string `json:"error,omitempty"` // error of evaluation
+}
 
-	if len(options) > 0 {
-		unique = options[0].Unique
+// MatchCategories match a row with categories in order of config, fields referred by filters but
+// not in row are empty. All categories are evaluated, with unique the row belongs to the first matched one.
+func MatchCategories(categories []Cate, row map[string]string) ([]CategoryMatch, error) {
+	fields := make([]string, 0, len(row))
+	for field := range row {
+		fields = append(fields, field)
 	}
+	fields = categoryFields(categories, fields)
 
-	config, err := LoadConfig(configFile)
+	rules, err := compileCategories(categories, fields)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	env := exprEnv(fields)
+	for field, value := range row {
+		env[field] = value
+	}
+	var matches []CategoryMatch
+	for i := range rules {
+		m := CategoryMatch{Name: rules[i].name}
+		m.Filter, err = rules[i].match(env)
+		if err != nil {
+			m.Error = err.Error()
+		}
+		m.Match = m.Filter != -1
+		matches = append(matches, m)
+	}
+	return matches, nil
+}
 
-	// 打开 CSV 文件进行读取
-	data, header, err := readformats.LoadCSVStreamed(inputFile)
+// loadRelation load values of targetField by values of sourceField in relation file,
+// values of the same source are joined by comma
+func loadRelation(relationFile, sourceField, targetField string, onSkip func(file string, err *readformats.RowError)) (map[string]string, error) {
+	rows, err := readformats.Iterate(relationFile, "")
 	if err != nil {
-		return "", fmt.Errorf("error opening CSV file: %v", err)
+		return nil, err
 	}
-
-	if len(data) == 0 {
-		return "", fmt.Errorf("no data found in input file: %s", inputFile)
+	defer rows.Close()
+	if onSkip != nil {
+		rows.OnSkip = func(err *readformats.RowError) {
+			onSkip(relationFile, err)
+		}
+	}
+	sourceIndex := slices.Index(rows.Fields(), sourceField)
+	targetIndex := slices.Index(rows.Fields(), targetField)
+	if sourceIndex == -1 || targetIndex == -1 {
+		return nil, fmt.Errorf("fields '%s' and '%s' should exist in relation file", sourceField, targetField)
 	}
 
-	// 创建输出文件的 writer 映射
-	writers := make(map[string]*csv.Writer)
-	// 创建一个存放result的文件夹
-	resultDir := filepath.Join("result", fmt.Sprintf(time.Now().Format("20060102150405")))
-	if err = os.MkdirAll(resultDir, os.ModePerm); err != nil {
-		return "", fmt.Errorf("error creating results directory: %v", err)
+	relation := make(map[string]string)
+	for rows.Next() {
+		record := rows.Record()
+		source, target := record[sourceIndex], record[targetIndex]
+		if len(source) == 0 || len(target) == 0 {
+			continue
+		}
+		if exist, ok := relation[source]; ok {
+			if !slices.Contains(strings.Split(exist, ","), target) {
+				relation[source] = exist + "," + target
+			}
+			continue
+		}
+		relation[source] = target
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
 	}
+	return relation, nil
+}
+
+// categoryWriter writer of rows of each category, or of all rows with tags
+type categoryWriter interface {
+	write(record []string, tags []string) error
+	close() error
+}
 
-	for _, cate := range config.Categories {
+// fileCategoryWriter one csv file per category
+type fileCategoryWriter struct {
+	files   []*os.File
+	writers map[string]*csv.Writer
+}
+
+func newFileCategoryWriter(dir string, header []string, summary *CategorySummary) (*fileCategoryWriter, error) {
+	w := &fileCategoryWriter{writers: make(map[string]*csv.Writer)}
+	for i, cate := range summary.Categories {
 		// 创建分类文件writer
-		fileName := filepath.Join(resultDir, cate.Name+".csv")
-		var file *os.File
-		file, err = os.Create(fileName)
+		fileName := filepath.Join(dir, cate.Name+".csv")
+		file, err := os.Create(fileName)
+		if err != nil {
+			w.close()
+			return nil, fmt.Errorf("error creating output file: %v", err)
+		}
+		w.files = append(w.files, file)
+		writer := csv.NewWriter(file)
+		// 写好表头
+		if err = writer.Write(header); err != nil {
+			w.close()
+			return nil, fmt.Errorf("error writing record: %v", err)
+		}
+		w.writers[cate.Name] = writer
+		summary.Categories[i].File = fileName
+	}
+	return w, nil
+}
+
+func (w *fileCategoryWriter) write(record []string, tags []string) error {
+	for _, tag := range tags {
+		if err := w.writers[tag].Write(record); err != nil {
+			return fmt.Errorf("error writing record: %v", err)
+		}
+	}
+	return nil
+}
+
+func (w *fileCategoryWriter) close() error {
+	var err error
+	for _, writer := range w.writers {
+		writer.Flush()
+		if e := writer.Error(); e != nil && err == nil {
+			err = e
+		}
+	}
+	for _, file := range w.files {
+		if e := file.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// tagCategoryWriter all rows to one writer with matched categories joined
+type tagCategoryWriter struct {
+	writer    outformats.OutWriter
+	separator string
+}
+
+func (w *tagCategoryWriter) write(record []string, tags []string) error {
+	record = append(slices.Clip(record), strings.Join(tags, w.separator))
+	if err := w.writer.WriteAll([][]string{record}); err != nil {
+		return fmt.Errorf("error writing record: %v", err)
+	}
+	return nil
+}
+
+func (w *tagCategoryWriter) close() error {
+	return outformats.Close(w.writer)
+}
+
+// Category classify rows of inputFile by filters of categories in configFile,
+// rows are written to one csv file per category, or to one writer with matched categories in tagging mode
+func Category(configFile, inputFile string, options ...CategoryOptions) (summary *CategorySummary, err error) {
+	var opts CategoryOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if len(opts.TagField) == 0 {
+		opts.TagField = CategoryTagField
+	}
+	if len(opts.TagSeparator) == 0 {
+		opts.TagSeparator = CategoryTagSeparator
+	}
+
+	categories := opts.Categories
+	if len(categories) == 0 {
+		var config *Config
+		config, err = LoadConfig(configFile)
+		if err != nil {
+			return nil, err
+		}
+		categories = config.Categories
+	}
+
+	// 逐行读取输入文件, csv/tsv/json/xml/xlsx
+	rows, err := readformats.Iterate(inputFile, opts.Format)
+	if err != nil {
+		return nil, fmt.Errorf("error opening input file: %v", err)
+	}
+	defer rows.Close()
+	if opts.OnSkip != nil {
+		rows.OnSkip = func(err *readformats.RowError) {
+			opts.OnSkip(inputFile, err)
+		}
+	}
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return nil, fmt.Errorf("error reading input file: %v", err)
+		}
+		return nil, fmt.Errorf("no data found in input file: %s", inputFile)
+	}
+	header := rows.Fields()
+
+	// 关联文件，按SourceField将TargetField写入每一行
+	var relation map[string]string
+	sourceIndex, targetIndex := -1, -1
+	if len(opts.RelationFile) > 0 {
+		if len(opts.SourceField) == 0 || len(opts.TargetField) == 0 {
+			return nil, fmt.Errorf("relation file needs source field and target field")
+		}
+		if sourceIndex = slices.Index(header, opts.SourceField); sourceIndex == -1 {
+			return nil, fmt.Errorf("source field '%s' not found in input file", opts.SourceField)
+		}
+		relation, err = loadRelation(opts.RelationFile, opts.SourceField, opts.TargetField, opts.OnSkip)
 		if err != nil {
-			return "", fmt.Errorf("error creating output file: %v", err)
+			return nil, fmt.Errorf("error loading relation file: %v", err)
+		}
+		if targetIndex = slices.Index(header, opts.TargetField); targetIndex == -1 {
+			targetIndex = len(header)
+			header = append(slices.Clip(header), opts.TargetField)
 		}
-		defer file.Close()
-		writers[cate.Name] = csv.NewWriter(file)
-		defer writers[cate.Name].Flush()
 	}
 
-	// 写好表头
-	for _, writer := range writers {
-		writer.Write(header)
+	// 规则只编译一次
+	rules, err := compileCategories(categories, header)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling filters: %v", err)
 	}
 
-	counts := make(map[string]int)
+	summary = &CategorySummary{}
+	for _, cate := range categories {
+		summary.Categories = append(summary.Categories, CategoryCount{Name: cate.Name})
+	}
+
+	var writer categoryWriter
+	if opts.NewWriter != nil {
+		if slices.Contains(header, opts.TagField) {
+			return nil, fmt.Errorf("tag field '%s' already exists in input file", opts.TagField)
+		}
+		var w outformats.OutWriter
+		fields := append(slices.Clip(header), opts.TagField)
+		w, err = opts.NewWriter(fields)
+		if err != nil {
+			return nil, fmt.Errorf("error creating output: %v", err)
+		}
+		if err = w.WriteHeader(fields); err != nil {
+			outformats.Close(w)
+			return nil, fmt.Errorf("error writing record: %v", err)
+		}
+		writer = &tagCategoryWriter{writer: w, separator: opts.TagSeparator}
+	} else {
+		// 创建一个存放result的文件夹
+		summary.Dir = opts.OutDir
+		if len(summary.Dir) == 0 {
+			summary.Dir = filepath.Join("result", time.Now().Format("20060102150405"))
+		}
+		if err = os.MkdirAll(summary.Dir, os.ModePerm); err != nil {
+			return nil, fmt.Errorf("error creating results directory: %v", err)
+		}
+		writer, err = newFileCategoryWriter(summary.Dir, header, summary)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer func() {
+		if e := writer.close(); e != nil && err == nil {
+			err = fmt.Errorf("error writing output: %v", e)
+		}
+	}()
 
 	// 根据分类标准打标签
-	var match bool
-	for _, recordMap := range data {
-		for _, cate := range config.Categories {
-			match, err = evaluateExpressions(cate.Filters, recordMap)
-			if err != nil {
-				log.Println("Error evaluating expressions:", err)
+	env := exprEnv(header)
+	var tags []string
+	for ok := true; ok; ok = rows.Next() {
+		record := rows.Record()
+		if relation != nil {
+			if targetIndex == len(record) {
+				record = append(slices.Clip(record), "")
+			}
+			if target, ok := relation[record[sourceIndex]]; ok {
+				record[targetIndex] = target
+			}
+		}
+		for i, field := range header {
+			env[field] = record[i]
+		}
+		tags = tags[:0]
+		for i := range rules {
+			index, e := rules[i].match(env)
+			if e != nil {
+				summary.Categories[i].Errors++
 				continue
 			}
-
-			if match {
-				row := make([]string, len(header))
-				for i, headerRow := range header {
-					row[i] = fmt.Sprintf("%v", recordMap[headerRow])
-				}
-				err = writers[cate.Name].Write(row)
-				if err != nil {
-					return "", fmt.Errorf("error writing record: %v", err)
-				}
-				// 匹配成功，写入分类文件
-				counts[cate.Name]++
-				if unique {
+			if index != -1 {
+				tags = append(tags, rules[i].name)
+				summary.Categories[i].Count++
+				if opts.Unique {
 					break
 				}
 			}
 		}
-
+		summary.Total++
+		if len(tags) > 0 {
+			summary.Matched++
+		}
+		if err = writer.write(record, tags); err != nil {
+			return nil, err
+		}
 	}
-
-	for _, cate := range config.Categories {
-		fmt.Println("[-] Matches category:", cate.Name, ", Length:", counts[cate.Name])
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading input file: %v", err)
 	}
+	summary.Skipped = rows.Skipped()
 
-	return resultDir, nil
+	return summary, nil
 }
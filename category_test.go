@@ -1,7 +1,9 @@
 package gofofa
 
 import (
+	"bytes"
 	"fmt"
+	"github.com/FofaInfo/GoFOFA/pkg/outformats"
 	"github.com/FofaInfo/GoFOFA/pkg/readformats"
 	"github.com/stretchr/testify/assert"
 	"os"
@@ -53,15 +55,29 @@ func TestCategory(t *testing.T) {
 
 	_, err = Category(tmpYAMLFile.Name(), "dsfhdksajfhsdkjfh", CategoryOptions{})
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "error opening CSV file")
+	assert.Contains(t, err.Error(), "error opening input file")
 
 	_, err = Category(errYAMLFile.Name(), tmpCSVFile.Name(), CategoryOptions{})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "error creating output file")
 
+	badYAMLFile := filepath.Join(tempDir, "bad_config.yaml")
+	assert.Nil(t, os.WriteFile(badYAMLFile, []byte("categories:\n  - name: \"hard\"\n    filters:\n      - \"CONTAIN(category, '数据证书')\"\n      - \"CONTAIN(unknown, 'x')\"\n"), 0644))
+	_, err = Category(badYAMLFile, tmpCSVFile.Name(), CategoryOptions{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "category hard filter 1")
+
 	// 正确检测
-	resultDir, err := Category(tmpYAMLFile.Name(), tmpCSVFile.Name(), CategoryOptions{})
+	summary, err := Category(tmpYAMLFile.Name(), tmpCSVFile.Name(), CategoryOptions{})
 	assert.Nil(t, err)
+	resultDir := summary.Dir
+	assert.Equal(t, 5, summary.Total)
+	assert.Equal(t, 5, summary.Matched)
+	assert.Equal(t, []CategoryCount{
+		{Name: "hard", Count: 2, File: filepath.Join(resultDir, "hard.csv")},
+		{Name: "soft", Count: 1, File: filepath.Join(resultDir, "soft.csv")},
+		{Name: "buss", Count: 3, File: filepath.Join(resultDir, "buss.csv")},
+	}, summary.Categories)
 
 	// 验证 result 文件夹存在
 	resultPath := filepath.Join(tempDir, "result")
@@ -97,3 +113,139 @@ func TestCategory(t *testing.T) {
 		}
 	}
 }
+
+func TestCategory_Options(t *testing.T) {
+	tempDir := t.TempDir()
+	inFile := filepath.Join(tempDir, "in.csv")
+	assert.Nil(t, os.WriteFile(inFile, []byte("host,product\na.com,nginx\nb.com,\"nginx,php\"\nc.com,iis\n"), 0644))
+	configFile := filepath.Join(tempDir, "config.yaml")
+	assert.Nil(t, os.WriteFile(configFile, []byte("categories:\n  - name: web\n    filters:\n      - \"CONTAIN(product, 'nginx')\"\n"+
+		"  - name: php\n    filters:\n      - \"CONTAIN(product, 'php')\"\n"), 0644))
+
+	// 指定输出目录
+	outDir := filepath.Join(tempDir, "out")
+	summary, err := Category(configFile, inFile, CategoryOptions{OutDir: outDir})
+	assert.Nil(t, err)
+	assert.Equal(t, outDir, summary.Dir)
+	data, err := os.ReadFile(filepath.Join(outDir, "php.csv"))
+	assert.Nil(t, err)
+	assert.Equal(t, "host,product\nb.com,\"nginx,php\"\n", string(data))
+
+	// 打标签模式
+	var buf bytes.Buffer
+	var fields []string
+	newWriter := func(f []string) (outformats.OutWriter, error) {
+		fields = f
+		return outformats.NewCSVWriter(&buf), nil
+	}
+	summary, err = Category(configFile, inFile, CategoryOptions{NewWriter: newWriter, TagSeparator: "|"})
+	assert.Nil(t, err)
+	assert.Empty(t, summary.Dir)
+	assert.Equal(t, 3, summary.Total)
+	assert.Equal(t, 2, summary.Matched)
+	assert.Equal(t, []CategoryCount{{Name: "web", Count: 2}, {Name: "php", Count: 1}}, summary.Categories)
+	assert.Equal(t, []string{"host", "product", "category"}, fields)
+	assert.Equal(t, "host,product,category\na.com,nginx,web\nb.com,\"nginx,php\",web|php\nc.com,iis,\n", buf.String())
+
+	buf.Reset()
+	_, err = Category(configFile, inFile, CategoryOptions{NewWriter: newWriter, Unique: true, TagField: "tags"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"host", "product", "tags"}, fields)
+	assert.Equal(t, "host,product,tags\na.com,nginx,web\nb.com,\"nginx,php\",web\nc.com,iis,\n", buf.String())
+
+	_, err = Category(configFile, inFile, CategoryOptions{NewWriter: newWriter, TagField: "host"})
+	assert.ErrorContains(t, err, "host")
+}
+
+func TestCategory_Relation(t *testing.T) {
+	tempDir := t.TempDir()
+	inFile := filepath.Join(tempDir, "in.csv")
+	assert.Nil(t, os.WriteFile(inFile, []byte("ip,port\n1.1.1.1,80\n2.2.2.2,443\n3.3.3.3,22\n"), 0644))
+	relationFile := filepath.Join(tempDir, "owners.csv")
+	assert.Nil(t, os.WriteFile(relationFile, []byte("ip,owner\n1.1.1.1,ops\n2.2.2.2,dev\n2.2.2.2,sec\n2.2.2.2,dev\n"), 0644))
+	configFile := filepath.Join(tempDir, "config.yaml")
+	assert.Nil(t, os.WriteFile(configFile, []byte("categories:\n  - name: dev\n    filters:\n      - \"CONTAIN(owner, 'dev')\"\n"+
+		"  - name: unowned\n    filters:\n      - \"owner == ''\"\n"), 0644))
+
+	var buf bytes.Buffer
+	newWriter := func(f []string) (outformats.OutWriter, error) {
+		return outformats.NewCSVWriter(&buf), nil
+	}
+	summary, err := Category(configFile, inFile, CategoryOptions{
+		RelationFile: relationFile,
+		SourceField:  "ip",
+		TargetField:  "owner",
+		NewWriter:    newWriter,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []CategoryCount{{Name: "dev", Count: 1}, {Name: "unowned", Count: 1}}, summary.Categories)
+	assert.Equal(t, "ip,port,owner,category\n1.1.1.1,80,ops,\n2.2.2.2,443,\"dev,sec\",dev\n3.3.3.3,22,,unowned\n", buf.String())
+
+	// 输入文件已有目标字段，关联到的值覆盖
+	assert.Nil(t, os.WriteFile(inFile, []byte("ip,owner\n1.1.1.1,\n3.3.3.3,dev\n"), 0644))
+	buf.Reset()
+	summary, err = Category(configFile, inFile, CategoryOptions{
+		RelationFile: relationFile,
+		SourceField:  "ip",
+		TargetField:  "owner",
+		NewWriter:    newWriter,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "ip,owner,category\n1.1.1.1,ops,\n3.3.3.3,dev,dev\n", buf.String())
+
+	_, err = Category(configFile, inFile, CategoryOptions{RelationFile: relationFile, SourceField: "host", TargetField: "owner"})
+	assert.ErrorContains(t, err, "host")
+	_, err = Category(configFile, inFile, CategoryOptions{RelationFile: relationFile, SourceField: "ip", TargetField: "team"})
+	assert.ErrorContains(t, err, "team")
+	_, err = Category(configFile, inFile, CategoryOptions{RelationFile: relationFile})
+	assert.Error(t, err)
+}
+
+func TestCheckCategories(t *testing.T) {
+	categories := []Cate{
+		{Name: "web", Filters: []string{"CONTAIN(title, 'nginx')", "int(port) == 80"}},
+		{Name: "broken", Filters: []string{"title == 'a'", "CONTAIN(title, 'a'", "owner == 'x'"}},
+	}
+	assert.Nil(t, CheckCategories(categories[:1], []string{"title", "port"}))
+	// 没有样本文件时使用规则引用的字段
+	errs := CheckCategories(categories, nil)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, 1, errs[0].Index)
+
+	errs = CheckCategories(categories, []string{"title", "port"})
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "broken", errs[0].Name)
+	assert.Equal(t, 1, errs[0].Index)
+	assert.Equal(t, 2, errs[1].Index)
+	assert.Contains(t, errs[1].Error(), "category broken filter 2:")
+
+	// 缺少字段
+	errs = CheckCategories(categories[:1], []string{"title"})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "int(port) == 80", errs[0].Filter)
+}
+
+func TestMatchCategories(t *testing.T) {
+	categories := []Cate{
+		{Name: "web", Filters: []string{"CONTAIN(title, 'nginx')", "int(port) == 80"}},
+		{Name: "private", Filters: []string{"inCIDR(ip, '10.0.0.0/8')"}},
+		{Name: "number", Filters: []string{"int(title) > 0"}},
+	}
+	matches, err := MatchCategories(categories, map[string]string{"title": "x", "port": "80"})
+	assert.Nil(t, err)
+	assert.Equal(t, []CategoryMatch{
+		{Name: "web", Match: true, Filter: 1},
+		{Name: "private", Match: false, Filter: -1},
+		{Name: "number", Match: false, Filter: -1, Error: matches[2].Error},
+	}, matches)
+	assert.Contains(t, matches[2].Error, "invalid int")
+
+	matches, err = MatchCategories(categories, map[string]string{"title": "nginx", "ip": "10.1.1.1"})
+	assert.Nil(t, err)
+	assert.True(t, matches[0].Match)
+	assert.Equal(t, 0, matches[0].Filter)
+	assert.True(t, matches[1].Match)
+
+	_, err = MatchCategories([]Cate{{Name: "broken", Filters: []string{"title =="}}}, map[string]string{})
+	assert.ErrorContains(t, err, "category broken filter 0")
+}
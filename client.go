@@ -6,6 +6,8 @@ env settings:
 - FOFA_SERVER fofa server
 - FOFA_EMAIL fofa account email
 - FOFA_KEY fofa account key
+- FOFA_CREDENTIAL name of credential stored by fofa auth login, used if no key is set, default is "default"
+- FOFA_PASSPHRASE passphrase of stored credentials
 */
 package gofofa
 
@@ -38,6 +40,7 @@ type Client struct {
 
 	onResults    func(results [][]string) // when fetch results callback
 	accountDebug bool                     // 调试账号明文信息
+	proxy        string                   // proxy url of requests
 }
 
 // Update merge config from config url
@@ -115,10 +118,78 @@ func WithAccountDebug(v bool) ClientOption {
 	}
 }
 
-// NewClient from fofa connection string to config
-// and with env config merge
-func NewClient(options ...ClientOption) (*Client, error) {
-	// read from env
+// WithProxy set proxy url of requests, like http://127.0.0.1:8080 or socks5://127.0.0.1:1080
+func WithProxy(proxy string) ClientOption {
+	return func(c *Client) error {
+		if len(proxy) > 0 {
+			if _, err := url.Parse(proxy); err != nil {
+				return fmt.Errorf("invalid proxy: %v", err)
+			}
+		}
+		c.proxy = proxy
+		return nil
+	}
+}
+
+// WithCredential merge server, email and key of credential, empty ones are not changed
+func WithCredential(credential Credential) ClientOption {
+	return func(c *Client) error {
+		if len(credential.Server) > 0 {
+			c.Server = credential.Server
+		}
+		if len(credential.Email) > 0 {
+			c.Email = credential.Email
+		}
+		if len(credential.Key) > 0 {
+			c.Key = credential.Key
+		}
+		return nil
+	}
+}
+
+// WithProfile merge settings of profile, empty ones are not changed
+func WithProfile(profile Profile) ClientOption {
+	return func(c *Client) error {
+		if len(profile.Server) > 0 {
+			c.Server = profile.Server
+		}
+		if len(profile.Email) > 0 {
+			c.Email = profile.Email
+		}
+		if len(profile.Key) > 0 {
+			c.Key = profile.Key
+		} else if len(profile.Credential) > 0 {
+			store, err := DefaultCredentialStore()
+			if err != nil {
+				return err
+			}
+			credential, ok, err := store.Get(profile.Credential)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("credential not found: %s, login by fofa auth login", profile.Credential)
+			}
+			if err = WithCredential(credential)(c); err != nil {
+				return err
+			}
+		}
+		switch profile.DeductMode {
+		case "":
+		case "0", "DeductModeFree", "1", "DeductModeFCoin":
+			c.DeductMode = ParseDeductMode(profile.DeductMode)
+		default:
+			return fmt.Errorf("unknown deduct mode: %s", profile.DeductMode)
+		}
+		if len(profile.Proxy) > 0 {
+			return WithProxy(profile.Proxy)(c)
+		}
+		return nil
+	}
+}
+
+// newClient client of env, then options
+func newClient(options []ClientOption) (*Client, error) {
 	c, err := newClientFromEnv()
 	if err != nil {
 		return c, err
@@ -131,9 +202,40 @@ func NewClient(options ...ClientOption) (*Client, error) {
 			return nil, err
 		}
 	}
+	return c, nil
+}
+
+// NewClient from fofa connection string to config
+// and with env config merge
+func NewClient(options ...ClientOption) (*Client, error) {
+	// read from env
+	c, err := newClient(options)
+	if err != nil {
+		return c, err
+	}
+
+	// 环境变量和参数都没有key时使用存储的凭据，参数仍然优先
+	if len(c.Key) == 0 {
+		credential, ok, err := storedCredential()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			c, err = newClient(append([]ClientOption{WithCredential(credential)}, options...))
+			if err != nil {
+				return c, err
+			}
+		}
+	}
 
 	// fetch one time to make sure network is ok
 	c.httpClient = &http.Client{}
+	if len(c.proxy) > 0 {
+		proxyURL, _ := url.Parse(c.proxy)
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxyURL)
+		c.httpClient.Transport = transport
+	}
 	c.Account, err = c.AccountInfo()
 	if err != nil {
 		c.logger.Warnf("account invalid")
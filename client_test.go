@@ -171,8 +171,8 @@ var (
 				switch r.FormValue("fields") {
 				case "link":
 					w.Write([]byte(`{"error":false,"size":386,"page":1,"mode":"extended","query":"domain=\"huashunxinan.net\"","results":["http://h8huumr2zdmwgy5.huashunxinan.net","http://keygatjexlvsznh.huashunxinan.net","http://jobs.huashunxinan.net","https://fwtn2k7oigaiyla.huashunxinan.net","http://autoconfig.jobs.huashunxinan.net","http://fwtn2k7oigaiyla.huashunxinan.net","http://www.panel.huashunxinan.net","http://panel.huashunxinan.net","https://autodiscover.search.huashunxinan.net","https://autoconfig.jobs.huashunxinan.net","https://jobs.huashunxinan.net","https://webdisk.radio.huashunxinan.net","https://radio.huashunxinan.net","https://search.huashunxinan.net","https://https.webdisk.radio.huashunxinan.net","https://https.www.facebook.huashunxinan.net","https://https.autodiscover.search.huashunxinan.net","https://h8huumr2zdmwgy5.huashunxinan.net","http://autoconfig.chat.huashunxinan.net","https://chat.huashunxinan.net","https://forms.huashunxinan.net","http://eedwwsqpoq1yjrf.huashunxinan.net","https://keygatjexlvsznh.huashunxinan.net","http://ervldyrbbspc83e.huashunxinan.net","http://chat.huashunxinan.net","https://ntusiro9kc9tw7g.keygatjexlvsznh.huashunxinan.net","http://jlt3h6kab162wx5.autoconfig.jobs.huashunxinan.net","https://panel.huashunxinan.net","https://wm52s3tfsy4e92s.fwtn2k7oigaiyla.huashunxinan.net","https://k3jylqlcgetbqqj.ervldyrbbspc83e.huashunxinan.net","http://w5ysiqo2nfqt7xy.h8huumr2zdmwgy5.huashunxinan.net","http://development.huashunxinan.net","http://idxvsbzg8hmakwa.h8huumr2zdmwgy5.huashunxinan.net","https://https.www.forms.huashunxinan.net","http://fvvh0xqy8pq99im.https.www.panel.huashunxinan.net","http://etwcxi7ihtx6e1j.https.www.forms.huashunxinan.net","https://jlt3h6kab162wx5.autoconfig.jobs.huashunxinan.net","http://hdjgghlkzrnr9jo.autoconfig.jobs.huashunxinan.net","https://ttzlop2i64rdybr.fwtn2k7oigaiyla.huashunxinan.net","https://idxvsbzg8hmakwa.h8huumr2zdmwgy5.huashunxinan.net","https://0xmq0eg8voxtlum.www.development.huashunxinan.net","http://wm52s3tfsy4e92s.fwtn2k7oigaiyla.huashunxinan.net","http://5wg4lxaqnrs65fa.autoconfig.chat.huashunxinan.net","http://by6fdwa63guytmv.https.www.panel.huashunxinan.net","https://www.forms.huashunxinan.net","https://ervldyrbbspc83e.huashunxinan.net","http://forms.huashunxinan.net","http://www.forms.huashunxinan.net","http://ntusiro9kc9tw7g.keygatjexlvsznh.huashunxinan.net","https://autoconfig.chat.huashunxinan.net","https://www.development.huashunxinan.net","https://3ebv9shrcbx8q4b.eedwwsqpoq1yjrf.huashunxinan.net","http://ttzlop2i64rdybr.fwtn2k7oigaiyla.huashunxinan.net","http://ny4haqvsq31hr31.ervldyrbbspc83e.huashunxinan.net","http://93pdg9kacxwmbgp.keygatjexlvsznh.huashunxinan.net","https://https.www.panel.huashunxinan.net","https://development.huashunxinan.net","http://gzna7ovvefwqgkp.www.development.huashunxinan.net","http://www.development.huashunxinan.net","https://hdjgghlkzrnr9jo.autoconfig.jobs.huashunxinan.net","https://glumonoexnxnuge.autoconfig.chat.huashunxinan.net","https://jwnzpo6n9jfyede.https.www.forms.huashunxinan.net","https://www.panel.huashunxinan.net","https://gzna7ovvefwqgkp.www.development.huashunxinan.net","https://w5ysiqo2nfqt7xy.h8huumr2zdmwgy5.huashunxinan.net","http://nncbybnspvbp47a.eedwwsqpoq1yjrf.huashunxinan.net","https://etwcxi7ihtx6e1j.https.www.forms.huashunxinan.net","http://3ebv9shrcbx8q4b.eedwwsqpoq1yjrf.huashunxinan.net","https://fvvh0xqy8pq99im.https.www.panel.huashunxinan.net","https://ny4haqvsq31hr31.ervldyrbbspc83e.huashunxinan.net","https://93pdg9kacxwmbgp.keygatjexlvsznh.huashunxinan.net","https://5wg4lxaqnrs65fa.autoconfig.chat.huashunxinan.net","http://jwnzpo6n9jfyede.https.www.forms.huashunxinan.net","https://nncbybnspvbp47a.eedwwsqpoq1yjrf.huashunxinan.net","http://glumonoexnxnuge.autoconfig.chat.huashunxinan.net","http://k3jylqlcgetbqqj.ervldyrbbspc83e.huashunxinan.net","https://by6fdwa63guytmv.https.www.panel.huashunxinan.net","http://0xmq0eg8voxtlum.www.development.huashunxinan.net","https://www.huashunxinan.net","http://huashunxinan.net","http://www.huashunxinan.net","https://huashunxinan.net","https://money.huashunxinan.net","https://b3laufkiusbjwzu.money.huashunxinan.net","http://b3laufkiusbjwzu.money.huashunxinan.net","http://money.huashunxinan.net","https://8ata9ukhdrrsohx.staff.huashunxinan.net","https://staff.huashunxinan.net","http://8ata9ukhdrrsohx.staff.huashunxinan.net","http://staff.huashunxinan.net","https://autodiscover.webmail.huashunxinan.net","https://https.webdisk.webmail.huashunxinan.net","https://webdisk.portal.huashunxinan.net","http://9sszcjiaidqbslq.gyros.huashunxinan.net","https://webdisk.webmail.huashunxinan.net","http://gyros.huashunxinan.net","http://webmail.huashunxinan.net","https://0junjqgxjrc9tyn.admin.huashunxinan.net","http://https.autoconfig.new.huashunxinan.net","http://https.autoconfig.new.huashunxinan.net"]}`))
-				case "link,ip,port,domain,title,fid":
-					w.Write([]byte(`{"error":false,"size":386,"page":1,"mode":"extended","query":"domain=\"huashunxinan.net\"","results":[["http://h8huumr2zdmwgy5.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://keygatjexlvsznh.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://jobs.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://fwtn2k7oigaiyla.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://autoconfig.jobs.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://fwtn2k7oigaiyla.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://www.panel.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://panel.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://autodiscover.search.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://autoconfig.jobs.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://jobs.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://webdisk.radio.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://radio.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://search.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://https.webdisk.radio.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://https.www.facebook.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://https.autodiscover.search.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://h8huumr2zdmwgy5.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://autoconfig.chat.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://chat.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://forms.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://eedwwsqpoq1yjrf.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://keygatjexlvsznh.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://ervldyrbbspc83e.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://chat.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://ntusiro9kc9tw7g.keygatjexlvsznh.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://jlt3h6kab162wx5.autoconfig.jobs.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://panel.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://wm52s3tfsy4e92s.fwtn2k7oigaiyla.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://k3jylqlcgetbqqj.ervldyrbbspc83e.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://w5ysiqo2nfqt7xy.h8huumr2zdmwgy5.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://development.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://idxvsbzg8hmakwa.h8huumr2zdmwgy5.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://https.www.forms.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://fvvh0xqy8pq99im.https.www.panel.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://etwcxi7ihtx6e1j.https.www.forms.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://jlt3h6kab162wx5.autoconfig.jobs.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://hdjgghlkzrnr9jo.autoconfig.jobs.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://ttzlop2i64rdybr.fwtn2k7oigaiyla.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://idxvsbzg8hmakwa.h8huumr2zdmwgy5.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://0xmq0eg8voxtlum.www.development.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://wm52s3tfsy4e92s.fwtn2k7oigaiyla.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://5wg4lxaqnrs65fa.autoconfig.chat.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://by6fdwa63guytmv.https.www.panel.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://www.forms.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://ervldyrbbspc83e.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://forms.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://www.forms.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://ntusiro9kc9tw7g.keygatjexlvsznh.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://autoconfig.chat.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://www.development.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://3ebv9shrcbx8q4b.eedwwsqpoq1yjrf.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://ttzlop2i64rdybr.fwtn2k7oigaiyla.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://ny4haqvsq31hr31.ervldyrbbspc83e.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://93pdg9kacxwmbgp.keygatjexlvsznh.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://https.www.panel.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://development.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://gzna7ovvefwqgkp.www.development.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://www.development.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://hdjgghlkzrnr9jo.autoconfig.jobs.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://glumonoexnxnuge.autoconfig.chat.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://jwnzpo6n9jfyede.https.www.forms.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://www.panel.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://gzna7ovvefwqgkp.www.development.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://w5ysiqo2nfqt7xy.h8huumr2zdmwgy5.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://nncbybnspvbp47a.eedwwsqpoq1yjrf.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://etwcxi7ihtx6e1j.https.www.forms.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://3ebv9shrcbx8q4b.eedwwsqpoq1yjrf.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://fvvh0xqy8pq99im.https.www.panel.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://ny4haqvsq31hr31.ervldyrbbspc83e.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://93pdg9kacxwmbgp.keygatjexlvsznh.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://5wg4lxaqnrs65fa.autoconfig.chat.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://jwnzpo6n9jfyede.https.www.forms.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://nncbybnspvbp47a.eedwwsqpoq1yjrf.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://glumonoexnxnuge.autoconfig.chat.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://k3jylqlcgetbqqj.ervldyrbbspc83e.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://by6fdwa63guytmv.https.www.panel.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://0xmq0eg8voxtlum.www.development.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://www.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://huashunxinan.net","117.50.23.106","80","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://www.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://money.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://b3laufkiusbjwzu.money.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://b3laufkiusbjwzu.money.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://money.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://8ata9ukhdrrsohx.staff.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://staff.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://8ata9ukhdrrsohx.staff.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://staff.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://autodiscover.webmail.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://https.webdisk.webmail.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://webdisk.portal.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://9sszcjiaidqbslq.gyros.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://webdisk.webmail.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://gyros.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://webmail.huashunxinan.net","117.50.23.106","80","huashunxinan.net","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://0junjqgxjrc9tyn.admin.huashunxinan.net","117.50.23.106","443","huashunxinan.net","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://https.autoconfig.new.huashunxinan.net","117.50.23.106","80","huashunxinan.net","",""],["http://https.autoconfig.new.huashunxinan.net","117.50.23.106","80","huashunxinan.net","",""]]}`))
+				case "link,ip,port,title,fid":
+					w.Write([]byte(`{"error":false,"size":386,"page":1,"mode":"extended","query":"domain=\"huashunxinan.net\"","results":[["http://h8huumr2zdmwgy5.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://keygatjexlvsznh.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://jobs.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://fwtn2k7oigaiyla.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://autoconfig.jobs.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://fwtn2k7oigaiyla.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://www.panel.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://panel.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://autodiscover.search.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://autoconfig.jobs.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://jobs.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://webdisk.radio.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://radio.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://search.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://https.webdisk.radio.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://https.www.facebook.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://https.autodiscover.search.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://h8huumr2zdmwgy5.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://autoconfig.chat.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://chat.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://forms.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://eedwwsqpoq1yjrf.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://keygatjexlvsznh.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://ervldyrbbspc83e.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://chat.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://ntusiro9kc9tw7g.keygatjexlvsznh.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://jlt3h6kab162wx5.autoconfig.jobs.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://panel.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://wm52s3tfsy4e92s.fwtn2k7oigaiyla.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://k3jylqlcgetbqqj.ervldyrbbspc83e.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://w5ysiqo2nfqt7xy.h8huumr2zdmwgy5.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://development.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://idxvsbzg8hmakwa.h8huumr2zdmwgy5.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://https.www.forms.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://fvvh0xqy8pq99im.https.www.panel.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://etwcxi7ihtx6e1j.https.www.forms.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://jlt3h6kab162wx5.autoconfig.jobs.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://hdjgghlkzrnr9jo.autoconfig.jobs.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://ttzlop2i64rdybr.fwtn2k7oigaiyla.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://idxvsbzg8hmakwa.h8huumr2zdmwgy5.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://0xmq0eg8voxtlum.www.development.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://wm52s3tfsy4e92s.fwtn2k7oigaiyla.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://5wg4lxaqnrs65fa.autoconfig.chat.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://by6fdwa63guytmv.https.www.panel.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://www.forms.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://ervldyrbbspc83e.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://forms.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://www.forms.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://ntusiro9kc9tw7g.keygatjexlvsznh.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://autoconfig.chat.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://www.development.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://3ebv9shrcbx8q4b.eedwwsqpoq1yjrf.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://ttzlop2i64rdybr.fwtn2k7oigaiyla.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://ny4haqvsq31hr31.ervldyrbbspc83e.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://93pdg9kacxwmbgp.keygatjexlvsznh.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://https.www.panel.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://development.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://gzna7ovvefwqgkp.www.development.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://www.development.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://hdjgghlkzrnr9jo.autoconfig.jobs.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://glumonoexnxnuge.autoconfig.chat.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://jwnzpo6n9jfyede.https.www.forms.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://www.panel.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://gzna7ovvefwqgkp.www.development.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://w5ysiqo2nfqt7xy.h8huumr2zdmwgy5.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://nncbybnspvbp47a.eedwwsqpoq1yjrf.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://etwcxi7ihtx6e1j.https.www.forms.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://3ebv9shrcbx8q4b.eedwwsqpoq1yjrf.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://fvvh0xqy8pq99im.https.www.panel.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://ny4haqvsq31hr31.ervldyrbbspc83e.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://93pdg9kacxwmbgp.keygatjexlvsznh.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://5wg4lxaqnrs65fa.autoconfig.chat.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://jwnzpo6n9jfyede.https.www.forms.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://nncbybnspvbp47a.eedwwsqpoq1yjrf.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://glumonoexnxnuge.autoconfig.chat.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://k3jylqlcgetbqqj.ervldyrbbspc83e.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://by6fdwa63guytmv.https.www.panel.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://0xmq0eg8voxtlum.www.development.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://www.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://huashunxinan.net","117.50.23.106","80","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://www.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://money.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://b3laufkiusbjwzu.money.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://b3laufkiusbjwzu.money.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://money.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://8ata9ukhdrrsohx.staff.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://staff.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://8ata9ukhdrrsohx.staff.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://staff.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://autodiscover.webmail.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://https.webdisk.webmail.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["https://webdisk.portal.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://9sszcjiaidqbslq.gyros.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://webdisk.webmail.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://gyros.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["http://webmail.huashunxinan.net","117.50.23.106","80","301 Moved Permanently","0FC01Psf64jTBZwBfHZoDg=="],["https://0junjqgxjrc9tyn.admin.huashunxinan.net","117.50.23.106","443","华顺信安-网络空间测绘的先行者","Ul341f8CLFF66hNsGHyoAQ=="],["http://https.autoconfig.new.huashunxinan.net","117.50.23.106","80","",""],["http://https.autoconfig.new.huashunxinan.net","117.50.23.106","80","",""]]}`))
 				case "link,type":
 					w.Write([]byte(`{"error":false,"size":386,"page":1,"mode":"extended","query":"domain=\"huashunxinan.net\"","results":[["http://h8huumr2zdmwgy5.huashunxinan.net","subdomain"],["http://keygatjexlvsznh.huashunxinan.net","subdomain"],["http://jobs.huashunxinan.net","subdomain"],["https://fwtn2k7oigaiyla.huashunxinan.net","subdomain"],["http://autoconfig.jobs.huashunxinan.net","subdomain"],["http://fwtn2k7oigaiyla.huashunxinan.net","subdomain"],["http://www.panel.huashunxinan.net","subdomain"],["http://panel.huashunxinan.net","subdomain"],["https://autodiscover.search.huashunxinan.net","subdomain"],["https://autoconfig.jobs.huashunxinan.net","subdomain"],["https://jobs.huashunxinan.net","subdomain"],["https://webdisk.radio.huashunxinan.net","subdomain"],["https://radio.huashunxinan.net","subdomain"],["https://search.huashunxinan.net","subdomain"],["https://https.webdisk.radio.huashunxinan.net","subdomain"],["https://https.www.facebook.huashunxinan.net","subdomain"],["https://https.autodiscover.search.huashunxinan.net","subdomain"],["https://h8huumr2zdmwgy5.huashunxinan.net","subdomain"],["http://autoconfig.chat.huashunxinan.net","subdomain"],["https://chat.huashunxinan.net","subdomain"],["https://forms.huashunxinan.net","subdomain"],["http://eedwwsqpoq1yjrf.huashunxinan.net","subdomain"],["https://keygatjexlvsznh.huashunxinan.net","subdomain"],["http://ervldyrbbspc83e.huashunxinan.net","subdomain"],["http://chat.huashunxinan.net","subdomain"],["https://ntusiro9kc9tw7g.keygatjexlvsznh.huashunxinan.net","subdomain"],["http://jlt3h6kab162wx5.autoconfig.jobs.huashunxinan.net","subdomain"],["https://panel.huashunxinan.net","subdomain"],["https://wm52s3tfsy4e92s.fwtn2k7oigaiyla.huashunxinan.net","subdomain"],["https://k3jylqlcgetbqqj.ervldyrbbspc83e.huashunxinan.net","subdomain"],["http://w5ysiqo2nfqt7xy.h8huumr2zdmwgy5.huashunxinan.net","subdomain"],["http://development.huashunxinan.net","subdomain"],["http://idxvsbzg8hmakwa.h8huumr2zdmwgy5.huashunxinan.net","subdomain"],["https://https.www.forms.huashunxinan.net","subdomain"],["http://fvvh0xqy8pq99im.https.www.panel.huashunxinan.net","subdomain"],["http://etwcxi7ihtx6e1j.https.www.forms.huashunxinan.net","subdomain"],["https://jlt3h6kab162wx5.autoconfig.jobs.huashunxinan.net","subdomain"],["http://hdjgghlkzrnr9jo.autoconfig.jobs.huashunxinan.net","subdomain"],["https://ttzlop2i64rdybr.fwtn2k7oigaiyla.huashunxinan.net","subdomain"],["https://idxvsbzg8hmakwa.h8huumr2zdmwgy5.huashunxinan.net","subdomain"],["https://0xmq0eg8voxtlum.www.development.huashunxinan.net","subdomain"],["http://wm52s3tfsy4e92s.fwtn2k7oigaiyla.huashunxinan.net","subdomain"],["http://5wg4lxaqnrs65fa.autoconfig.chat.huashunxinan.net","subdomain"],["http://by6fdwa63guytmv.https.www.panel.huashunxinan.net","subdomain"],["https://www.forms.huashunxinan.net","subdomain"],["https://ervldyrbbspc83e.huashunxinan.net","subdomain"],["http://forms.huashunxinan.net","subdomain"],["http://www.forms.huashunxinan.net","subdomain"],["http://ntusiro9kc9tw7g.keygatjexlvsznh.huashunxinan.net","subdomain"],["https://autoconfig.chat.huashunxinan.net","subdomain"],["https://www.development.huashunxinan.net","subdomain"],["https://3ebv9shrcbx8q4b.eedwwsqpoq1yjrf.huashunxinan.net","subdomain"],["http://ttzlop2i64rdybr.fwtn2k7oigaiyla.huashunxinan.net","subdomain"],["http://ny4haqvsq31hr31.ervldyrbbspc83e.huashunxinan.net","subdomain"],["http://93pdg9kacxwmbgp.keygatjexlvsznh.huashunxinan.net","subdomain"],["https://https.www.panel.huashunxinan.net","subdomain"],["https://development.huashunxinan.net","subdomain"],["http://gzna7ovvefwqgkp.www.development.huashunxinan.net","subdomain"],["http://www.development.huashunxinan.net","subdomain"],["https://hdjgghlkzrnr9jo.autoconfig.jobs.huashunxinan.net","subdomain"],["https://glumonoexnxnuge.autoconfig.chat.huashunxinan.net","subdomain"],["https://jwnzpo6n9jfyede.https.www.forms.huashunxinan.net","subdomain"],["https://www.panel.huashunxinan.net","subdomain"],["https://gzna7ovvefwqgkp.www.development.huashunxinan.net","subdomain"],["https://w5ysiqo2nfqt7xy.h8huumr2zdmwgy5.huashunxinan.net","subdomain"],["http://nncbybnspvbp47a.eedwwsqpoq1yjrf.huashunxinan.net","subdomain"],["https://etwcxi7ihtx6e1j.https.www.forms.huashunxinan.net","subdomain"],["http://3ebv9shrcbx8q4b.eedwwsqpoq1yjrf.huashunxinan.net","subdomain"],["https://fvvh0xqy8pq99im.https.www.panel.huashunxinan.net","subdomain"],["https://ny4haqvsq31hr31.ervldyrbbspc83e.huashunxinan.net","subdomain"],["https://93pdg9kacxwmbgp.keygatjexlvsznh.huashunxinan.net","subdomain"],["https://5wg4lxaqnrs65fa.autoconfig.chat.huashunxinan.net","subdomain"],["http://jwnzpo6n9jfyede.https.www.forms.huashunxinan.net","subdomain"],["https://nncbybnspvbp47a.eedwwsqpoq1yjrf.huashunxinan.net","subdomain"],["http://glumonoexnxnuge.autoconfig.chat.huashunxinan.net","subdomain"],["http://k3jylqlcgetbqqj.ervldyrbbspc83e.huashunxinan.net","subdomain"],["https://by6fdwa63guytmv.https.www.panel.huashunxinan.net","subdomain"],["http://0xmq0eg8voxtlum.www.development.huashunxinan.net","subdomain"],["https://www.huashunxinan.net","subdomain"],["http://huashunxinan.net","subdomain"],["http://www.huashunxinan.net","subdomain"],["https://huashunxinan.net","subdomain"],["https://money.huashunxinan.net","subdomain"],["https://b3laufkiusbjwzu.money.huashunxinan.net","subdomain"],["http://b3laufkiusbjwzu.money.huashunxinan.net","subdomain"],["http://money.huashunxinan.net","subdomain"],["https://8ata9ukhdrrsohx.staff.huashunxinan.net","subdomain"],["https://staff.huashunxinan.net","subdomain"],["http://8ata9ukhdrrsohx.staff.huashunxinan.net","subdomain"],["http://staff.huashunxinan.net","subdomain"],["https://autodiscover.webmail.huashunxinan.net","subdomain"],["https://https.webdisk.webmail.huashunxinan.net","subdomain"],["https://webdisk.portal.huashunxinan.net","subdomain"],["http://9sszcjiaidqbslq.gyros.huashunxinan.net","subdomain"],["https://webdisk.webmail.huashunxinan.net","subdomain"],["http://gyros.huashunxinan.net","subdomain"],["http://webmail.huashunxinan.net","subdomain"],["https://0junjqgxjrc9tyn.admin.huashunxinan.net","subdomain"],["http://https.autoconfig.new.huashunxinan.net","subdomain"],["http://https.autoconfig.new.huashunxinan.net","service"]]}`))
 				case "ip,host,status_code":
@@ -396,3 +396,73 @@ func TestNewClient(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotContains(t, u, account.Email)
 }
+
+func TestNewClient_Profile(t *testing.T) {
+	defer os.Unsetenv("FOFA_CLIENT_URL")
+
+	ts := httptest.NewServer(http.HandlerFunc(queryHander))
+	defer ts.Close()
+
+	// profile覆盖环境变量
+	account := validAccounts[1]
+	os.Setenv("FOFA_CLIENT_URL", ts.URL+"/?email=a@a.com&key=0&version=v1")
+	cli, err := NewClient(WithProfile(Profile{Server: ts.URL, Email: account.Email, Key: account.Key, DeductMode: "DeductModeFCoin"}))
+	assert.Nil(t, err)
+	assert.Equal(t, account.Email, cli.Email)
+	assert.Equal(t, account.FCoin, cli.Account.FCoin)
+	assert.Equal(t, DeductModeFCoin, cli.DeductMode)
+
+	// 代理
+	var proxied bool
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = true
+		queryHander(w, r)
+	}))
+	defer proxy.Close()
+	_, err = NewClient(WithProfile(Profile{Server: "http://fofa.invalid", Email: account.Email, Key: account.Key, Proxy: proxy.URL}))
+	assert.Nil(t, err)
+	assert.True(t, proxied)
+
+	_, err = NewClient(WithProfile(Profile{DeductMode: "free"}))
+	assert.ErrorContains(t, err, "unknown deduct mode")
+
+	// profile引用存储的凭据
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("FOFA_PASSPHRASE", "")
+	store, err := DefaultCredentialStore()
+	assert.Nil(t, err)
+	assert.Nil(t, store.Set("work", Credential{Server: ts.URL, Email: account.Email, Key: account.Key}))
+	cli, err = NewClient(WithProfile(Profile{Credential: "work"}))
+	assert.Nil(t, err)
+	assert.Equal(t, account.Key, cli.Key)
+	_, err = NewClient(WithProfile(Profile{Credential: "home"}))
+	assert.ErrorContains(t, err, "credential not found")
+}
+
+func TestNewClient_Credential(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(queryHander))
+	defer ts.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("FOFA_CLIENT_URL", "")
+	t.Setenv("FOFA_KEY", "")
+	t.Setenv("FOFA_PASSPHRASE", "")
+	account := validAccounts[1]
+	store, err := DefaultCredentialStore()
+	assert.Nil(t, err)
+	assert.Nil(t, store.Set(DefaultCredential, Credential{Server: "http://fofa.invalid", Email: account.Email, Key: account.Key}))
+
+	// 没有key时使用存储的凭据，参数优先
+	cli, err := NewClient(WithURL(ts.URL))
+	assert.Nil(t, err)
+	assert.Equal(t, ts.URL, cli.Server)
+	assert.Equal(t, account.Key, cli.Key)
+
+	// 凭据文件损坏时，指定key不受影响
+	assert.Nil(t, os.WriteFile(store.File, []byte("{bad"), 0600))
+	_, err = NewClient(WithURL(ts.URL))
+	assert.ErrorContains(t, err, "invalid credential file")
+	cli, err = NewClient(WithURL(ts.URL + "/?email=" + account.Email + "&key=" + account.Key))
+	assert.Nil(t, err)
+	assert.Equal(t, account.Key, cli.Key)
+}
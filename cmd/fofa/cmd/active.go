@@ -19,7 +19,7 @@ var (
 var activeCmd = &cli.Command{
 	Name:  "active",
 	Usage: "website active",
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:        "url",
 			Aliases:     []string{"u"},
@@ -39,10 +39,12 @@ var activeCmd = &cli.Command{
 			Usage:       "input file to build template if not use pipeline mode",
 			Destination: &inFile,
 		},
+		inFormatFlag(),
+		inFieldFlag("link", "url", "host"),
 		&cli.StringFlag{
 			Name:        "format",
 			Value:       "csv",
-			Usage:       "can be csv/json/xml",
+			Usage:       "can be csv/json/json-array/xml/template/geojson/nmap/masscan/targets/stix/misp/esbulk/sqlite/parquet/tsv",
 			Destination: &format,
 		},
 		&cli.IntFlag{
@@ -57,7 +59,7 @@ var activeCmd = &cli.Command{
 			Usage:       "timeout retry count",
 			Destination: &retry,
 		},
-	},
+	}, outputFlags()...),
 	Action: ActiveAction,
 }
 
@@ -70,30 +72,12 @@ func ActiveAction(ctx *cli.Context) error {
 	}
 
 	// gen output
-	var outTo io.Writer
-	if len(outFile) > 0 {
-		f, err := os.Create(outFile)
-		if err != nil {
-			return fmt.Errorf("create outFile %s failed: %w", outFile, err)
-		}
-		outTo = f
-		defer f.Close()
-	} else {
-		outTo = os.Stdout
-	}
 
 	// gen writer
 	headFields := []string{"url", "isActive"}
-	var writer outformats.OutWriter
-	switch format {
-	case "csv":
-		writer = outformats.NewCSVWriter(outTo)
-	case "json":
-		writer = outformats.NewJSONWriter(outTo, headFields)
-	case "xml":
-		writer = outformats.NewXMLWriter(outTo, headFields)
-	default:
-		return fmt.Errorf("unknown format: %s", format)
+	writer, err := newOutWriter(headFields)
+	if err != nil {
+		return err
 	}
 
 	var locker sync.Mutex
@@ -115,11 +99,13 @@ func ActiveAction(ctx *cli.Context) error {
 	}
 
 	if len(activeTarget) != 0 {
-		return writeURL(activeTarget)
+		if err = writeURL(activeTarget); err != nil {
+			return err
+		}
 	} else {
 		var inf io.Reader
 		if inFile != "" {
-			f, err := os.Open(inFile)
+			f, err := openInFile("link", "url", "host")
 			if err != nil {
 				return err
 			}
@@ -131,5 +117,5 @@ func ActiveAction(ctx *cli.Context) error {
 		concurrentPipeline(writeURL, inf)
 	}
 
-	return nil
+	return outformats.Close(writer)
 }
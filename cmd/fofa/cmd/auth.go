@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/FofaInfo/GoFOFA"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+	"os"
+	"strings"
+)
+
+var (
+	credentialName   string // name of stored credential
+	credentialServer string // server of stored credential
+	credentialEmail  string // email of stored credential
+	usePassphrase    bool   // encrypt credentials by passphrase instead of key file
+)
+
+// stdinReader reader of prompts, shared so that piped lines are not lost by buffering
+var stdinReader = bufio.NewReader(os.Stdin)
+
+func credentialNameFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:        "name",
+		Usage:       "name of credential, default is credential of profile, env FOFA_CREDENTIAL or default",
+		Destination: &credentialName,
+	}
+}
+
+var authCmd = &cli.Command{
+	Name:  "auth",
+	Usage: "store fofa key in an encrypted local file",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "login",
+			Usage: "verify and store credential, key is read from stdin",
+			Flags: []cli.Flag{
+				credentialNameFlag(),
+				&cli.StringFlag{
+					Name:        "server",
+					Usage:       "fofa server, format: <scheme>://<host>, default is https://fofa.info",
+					Destination: &credentialServer,
+				},
+				&cli.StringFlag{
+					Name:        "email",
+					Usage:       "fofa email",
+					Destination: &credentialEmail,
+				},
+				&cli.BoolFlag{
+					Name:        "passphrase",
+					Usage:       "encrypt by passphrase of env FOFA_PASSPHRASE or prompt, instead of key file",
+					Destination: &usePassphrase,
+				},
+			},
+			Action: authLoginAction,
+		},
+		{
+			Name:   "logout",
+			Usage:  "remove stored credential",
+			Flags:  []cli.Flag{credentialNameFlag()},
+			Action: authLogoutAction,
+		},
+		{
+			Name:   "status",
+			Usage:  "show stored credential with masked key and account information",
+			Flags:  []cli.Flag{credentialNameFlag()},
+			Action: authStatusAction,
+		},
+	},
+}
+
+// readLine read a line of stdin after prompt to stderr
+func readLine(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := stdinReader.ReadString('\n')
+	line = strings.TrimSpace(line)
+	if err != nil && len(line) == 0 {
+		return "", fmt.Errorf("read %s failed: %v", strings.TrimSuffix(strings.ToLower(prompt), ": "), err)
+	}
+	return line, nil
+}
+
+// readSecret read a secret without echo if stdin is a terminal, otherwise a line of piped stdin
+func readSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return readLine(prompt)
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	secret, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("read %s failed: %v", strings.TrimSuffix(strings.ToLower(prompt), ": "), err)
+	}
+	return strings.TrimSpace(string(secret)), nil
+}
+
+// resolveCredentialName name of flag, then credential of profile, env FOFA_CREDENTIAL and default
+func resolveCredentialName() (string, error) {
+	if len(credentialName) > 0 {
+		return credentialName, nil
+	}
+	if fofaConfig != nil {
+		profile, ok, err := fofaConfig.GetProfile(profileName)
+		if err != nil {
+			return "", err
+		}
+		if ok && len(profile.Credential) > 0 {
+			return profile.Credential, nil
+		}
+	}
+	if name := os.Getenv("FOFA_CREDENTIAL"); len(name) > 0 {
+		return name, nil
+	}
+	return gofofa.DefaultCredential, nil
+}
+
+// openCredentialStore default store, passphrase is prompted if needed and not set by env
+func openCredentialStore(passphrase bool) (*gofofa.CredentialStore, error) {
+	store, err := gofofa.DefaultCredentialStore()
+	if err != nil {
+		return nil, err
+	}
+	mode, err := store.Mode()
+	if err != nil {
+		return nil, err
+	}
+	if (passphrase || mode == gofofa.CredentialModePassphrase) && len(store.Passphrase) == 0 {
+		if store.Passphrase, err = readSecret("Passphrase: "); err != nil {
+			return nil, err
+		}
+		if len(store.Passphrase) == 0 {
+			return nil, errors.New("passphrase cannot be empty")
+		}
+		// 新设置的口令需要确认，避免输错后无法解密
+		if mode != gofofa.CredentialModePassphrase {
+			confirm, err := readSecret("Confirm passphrase: ")
+			if err != nil {
+				return nil, err
+			}
+			if confirm != store.Passphrase {
+				return nil, errors.New("passphrases do not match")
+			}
+		}
+	}
+	return store, nil
+}
+
+func authLoginAction(ctx *cli.Context) error {
+	name, err := resolveCredentialName()
+	if err != nil {
+		return err
+	}
+	store, err := openCredentialStore(usePassphrase)
+	if err != nil {
+		return err
+	}
+	// 先确认已有凭据可以解密
+	if _, err = store.Load(); err != nil {
+		return err
+	}
+
+	credential := gofofa.Credential{Server: credentialServer, Email: credentialEmail}
+	if credential.Key, err = readSecret("FOFA key: "); err != nil {
+		return err
+	}
+	if len(credential.Key) == 0 {
+		return errors.New("key cannot be empty")
+	}
+
+	// 保存前校验账号
+	if _, err = gofofa.NewClient(gofofa.WithCredential(credential)); err != nil {
+		return err
+	}
+	if err = store.Set(name, credential); err != nil {
+		return fmt.Errorf("save credential failed: %v", err)
+	}
+	fmt.Println("[-] Logged in, credential:", name)
+	fmt.Println("[-] Credentials file:", store.File)
+	return nil
+}
+
+func authLogoutAction(ctx *cli.Context) error {
+	name, err := resolveCredentialName()
+	if err != nil {
+		return err
+	}
+	store, err := openCredentialStore(false)
+	if err != nil {
+		return err
+	}
+	ok, err := store.Delete(name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("credential not found: %s", name)
+	}
+	fmt.Println("[-] Removed credential:", name)
+	return nil
+}
+
+func authStatusAction(ctx *cli.Context) error {
+	name, err := resolveCredentialName()
+	if err != nil {
+		return err
+	}
+	store, err := openCredentialStore(false)
+	if err != nil {
+		return err
+	}
+	mode, err := store.Mode()
+	if err != nil {
+		return err
+	}
+	credential, ok, err := store.Get(name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("credential not found: %s, login by fofa auth login", name)
+	}
+
+	fmt.Println("[-] Credential:", name)
+	fmt.Printf("[-] Credentials file: %s (%s)\n", store.File, mode)
+	if len(credential.Server) > 0 {
+		fmt.Println("[-] Server:", credential.Server)
+	}
+	if len(credential.Email) > 0 {
+		fmt.Println("[-] Email:", credential.Email)
+	}
+	fmt.Println("[-] Key:", credential.MaskedKey())
+
+	client, err := gofofa.NewClient(gofofa.WithCredential(credential))
+	if err != nil {
+		return err
+	}
+	fmt.Println(client.Account)
+	return nil
+}
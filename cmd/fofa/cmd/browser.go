@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"github.com/FofaInfo/GoFOFA"
 	"github.com/FofaInfo/GoFOFA/pkg/outformats"
-	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 	"io"
 	"log"
@@ -23,7 +22,7 @@ var (
 var browserCmd = &cli.Command{
 	Name:  "jsRender",
 	Usage: "website js render",
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:        "url",
 			Aliases:     []string{"u"},
@@ -50,10 +49,12 @@ var browserCmd = &cli.Command{
 			Usage:       "input file to build template if not use pipeline mode",
 			Destination: &inFile,
 		},
+		inFormatFlag(),
+		inFieldFlag("link", "url", "host"),
 		&cli.StringFlag{
 			Name:        "format",
 			Value:       "csv",
-			Usage:       "can be csv/json/xml",
+			Usage:       "can be csv/json/json-array/xml/template/geojson/nmap/masscan/targets/stix/misp/esbulk/sqlite/parquet/tsv",
 			Destination: &format,
 		},
 		&cli.IntFlag{
@@ -68,7 +69,7 @@ var browserCmd = &cli.Command{
 			Usage:       "timeout retry count",
 			Destination: &retry,
 		},
-	},
+	}, outputFlags()...),
 	Action: BrowserAction,
 }
 
@@ -122,36 +123,12 @@ func BrowserAction(ctx *cli.Context) error {
 	tags := strings.Split(browserTags, ",")
 
 	// gen output
-	var outTo io.Writer
-	if len(outFile) > 0 {
-		var f *os.File
-		var err error
-		if f, err = os.Create(outFile); err != nil {
-			return fmt.Errorf("create outFile %s failed: %w", outFile, err)
-		}
-		outTo = f
-		defer f.Close()
-	} else {
-		outTo = os.Stdout
-	}
 
 	headFields := []string{"url"}
 	headFields = append(headFields, tags...)
-	var writer outformats.OutWriter
-	if hasBodyField(tags) && format == "csv" {
-		logrus.Warnln("fields contains body, so change format to json")
-		writer = outformats.NewJSONWriter(outTo, headFields)
-	} else {
-		switch format {
-		case "csv":
-			writer = outformats.NewCSVWriter(outTo)
-		case "json":
-			writer = outformats.NewJSONWriter(outTo, headFields)
-		case "xml":
-			writer = outformats.NewXMLWriter(outTo, headFields)
-		default:
-			return fmt.Errorf("unknown format: %s", format)
-		}
+	writer, err := newOutWriter(headFields)
+	if err != nil {
+		return err
 	}
 
 	var locker sync.Mutex
@@ -179,11 +156,13 @@ func BrowserAction(ctx *cli.Context) error {
 	}
 
 	if browserURL != "" {
-		return writeURL(browserURL)
+		if err = writeURL(browserURL); err != nil {
+			return err
+		}
 	} else {
 		var inf io.Reader
 		if inFile != "" {
-			f, err := os.Open(inFile)
+			f, err := openInFile("link", "url", "host")
 			if err != nil {
 				return err
 			}
@@ -194,5 +173,5 @@ func BrowserAction(ctx *cli.Context) error {
 		}
 		concurrentPipeline(writeURL, inf)
 	}
-	return nil
+	return outformats.Close(writer)
 }
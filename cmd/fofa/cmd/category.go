@@ -1,92 +1,316 @@
 package cmd
 
 import (
+	stdjson "encoding/json"
 	"errors"
 	"fmt"
 	"github.com/FofaInfo/GoFOFA"
+	"github.com/FofaInfo/GoFOFA/pkg/outformats"
 	"github.com/FofaInfo/GoFOFA/pkg/readformats"
 	"github.com/urfave/cli/v2"
 	"os"
-	"path/filepath"
-)
-
-const (
-	ConfigFileName = "config.yaml"
+	"slices"
+	"strings"
 )
 
 var (
-	unique bool // is the classification unique
+	unique       bool   // is the classification unique
+	categoryTag  bool   // append matched categories to one output instead of one file per category
+	tagField     string // field of matched categories
+	tagSeparator string // separator of matched categories
+	categoryDir  string // dir of one csv file per category
+	relationFile string // relation file joined into each row
+	sourceField  string // field of input joined with relation file
+	targetField  string // field of relation file written into each row
+	categoryRow  string // json object of a row to test categories
 )
 
 var categoryCmd = &cli.Command{
 	Name:                   "category",
 	Usage:                  "classify data according to config",
 	UseShortOptionHandling: true,
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:        "inFile",
 			Aliases:     []string{"i"},
-			Usage:       "input data file",
+			Usage:       "input data file, can be csv/tsv/json/xml/xlsx",
 			Destination: &inFile,
 		},
+		inFormatFlag(),
 		&cli.BoolFlag{
 			Name:        "unique",
 			Value:       false,
 			Usage:       "is the classification unique",
 			Destination: &unique,
 		},
+		&cli.StringFlag{
+			Name:        "relation",
+			Usage:       "relation file like owners.csv, whose target field is joined into each record by source field",
+			Destination: &relationFile,
+		},
+		&cli.StringFlag{
+			Name:        "source",
+			Usage:       "field of input joined with the same field of relation file, like ip",
+			Destination: &sourceField,
+		},
+		&cli.StringFlag{
+			Name:        "target",
+			Usage:       "field of relation file written into each record, like owner",
+			Destination: &targetField,
+		},
+		&cli.StringFlag{
+			Name:        "outDir",
+			Usage:       "dir of one csv file per category, default is result/<timestamp>",
+			Destination: &categoryDir,
+		},
+		&cli.BoolFlag{
+			Name:        "tag",
+			Usage:       "write all records to one output with a field of matched categories, instead of one file per category",
+			Destination: &categoryTag,
+		},
+		&cli.StringFlag{
+			Name:        "tagField",
+			Value:       gofofa.CategoryTagField,
+			Usage:       "field of matched categories of tag",
+			Destination: &tagField,
+		},
+		&cli.StringFlag{
+			Name:        "tagSeparator",
+			Value:       gofofa.CategoryTagSeparator,
+			Usage:       "separator of matched categories of tag",
+			Destination: &tagSeparator,
+		},
+		&cli.StringFlag{
+			Name:        "outFile",
+			Aliases:     []string{"o"},
+			Usage:       "output file of tag, if not set, write to stdout",
+			Destination: &outFile,
+		},
+		&cli.StringFlag{
+			Name:        "format",
+			Value:       "csv",
+			Usage:       "format of output of tag, can be csv/json/json-array/xml/template/geojson/nmap/masscan/targets/stix/misp/esbulk/sqlite/parquet/tsv",
+			Destination: &format,
+		},
+	}, outputFlags()...),
+	Subcommands: []*cli.Command{
+		categoryCheckCmd,
+		categoryTestCmd,
 	},
 
 	Action: categoryAction,
 }
 
+var categoryCheckCmd = &cli.Command{
+	Name:  "check",
+	Usage: "compile filters of config against fields of a sample file",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:        "inFile",
+			Aliases:     []string{"i"},
+			Usage:       "sample data file, can be csv/tsv/json/xml/xlsx, if not set, fields referred by filters are used",
+			Destination: &inFile,
+		},
+		inFormatFlag(),
+		&cli.StringFlag{
+			Name:        "target",
+			Usage:       "field of relation file written into each record, like owner",
+			Destination: &targetField,
+		},
+	},
+	Action: categoryCheckAction,
+}
+
+var categoryTestCmd = &cli.Command{
+	Name:  "test",
+	Usage: "show which categories of config a row matches",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:        "row",
+			Usage:       "json object of a row, like {\"title\":\"x\"}",
+			Required:    true,
+			Destination: &categoryRow,
+		},
+		&cli.BoolFlag{
+			Name:        "unique",
+			Value:       false,
+			Usage:       "is the classification unique",
+			Destination: &unique,
+		},
+	},
+	Action: categoryTestAction,
+}
+
+// loadCategoryConfig config of all layers, categories should not be empty
+func loadCategoryConfig() (*gofofa.Config, error) {
+	if fofaConfig != nil && fofaConfig.LegacyError != nil {
+		return nil, fofaConfig.LegacyError
+	}
+	if fofaConfig == nil || len(fofaConfig.Files) == 0 {
+		return nil, errors.New("not found config, set by --config, ./.fofa.yaml or ./config.yaml")
+	}
+	if len(fofaConfig.Categories) == 0 {
+		return nil, errors.New("categories not be empty")
+	}
+	return fofaConfig, nil
+}
+
 func categoryAction(ctx *cli.Context) error {
 	// 检测无效参数
 	if len(ctx.Args().Slice()) > 0 {
 		return errors.New("invalid arguments")
 	}
 
-	// 查找当前目录下是否有config.yaml文件
-	currentDir, err := os.Getwd()
+	config, err := loadCategoryConfig()
 	if err != nil {
-		return fmt.Errorf("error getting current directory: %s", err.Error())
+		return err
 	}
 
-	found := false
-	filepath.Walk(currentDir, func(path string, info os.FileInfo, err error) error {
-		if err == nil && !info.IsDir() && info.Name() == ConfigFileName {
-			found = true
-			return filepath.SkipDir // 找到后停止查找
+	// 检测input是否为空
+	if len(inFile) == 0 {
+		return errors.New("no input file")
+	}
+
+	options := gofofa.CategoryOptions{
+		Categories:   config.Categories,
+		Unique:       unique,
+		Format:       inFormat,
+		OutDir:       categoryDir,
+		TagField:     tagField,
+		TagSeparator: tagSeparator,
+		RelationFile: relationFile,
+		SourceField:  sourceField,
+		TargetField:  targetField,
+		OnSkip:       warnSkippedRow,
+	}
+	if categoryTag {
+		if len(categoryDir) > 0 {
+			return errors.New("outDir cannot be used with tag")
+		}
+		options.NewWriter = func(fields []string) (outformats.OutWriter, error) {
+			// header is written by category
+			headline = false
+			return newOutWriter(fields)
 		}
-		return nil
-	})
+	}
+	summary, err := gofofa.Category("", inFile, options)
+	if err != nil {
+		return errors.New("category error: " + err.Error())
+	}
 
-	if !found {
-		return errors.New("not found config.yaml")
+	// 输出到stdout时，统计信息输出到stderr
+	out := os.Stdout
+	if categoryTag && len(outFile) == 0 {
+		out = os.Stderr
+	}
+	for _, cate := range summary.Categories {
+		fmt.Fprintln(out, "[-] Matches category:", cate.Name, ", Length:", cate.Count)
+		if cate.Errors > 0 {
+			fmt.Fprintln(out, "[-] Failed to evaluate category:", cate.Name, ", Length:", cate.Errors)
+		}
+	}
+	if len(summary.Dir) > 0 {
+		fmt.Fprintln(out, "[-] Results dir:", summary.Dir)
+	}
+	fmt.Fprintf(out, "[-] Matched %d of %d records\n", summary.Matched, summary.Total)
+	if summary.Skipped > 0 {
+		fmt.Fprintf(out, "[-] Skipped %d records which can't be aligned with the header\n", summary.Skipped)
+	}
+
+	return nil
+}
+
+func categoryCheckAction(ctx *cli.Context) error {
+	// 检测无效参数
+	if len(ctx.Args().Slice()) > 0 {
+		return errors.New("invalid arguments")
 	}
 
-	// 检测config文件内容是否合规
-	yamlReader := readformats.NewYAMLReader(ConfigFileName)
-	var config gofofa.Config
-	err = yamlReader.UnmarshalFile(&config)
+	config, err := loadCategoryConfig()
 	if err != nil {
-		return fmt.Errorf("error reading YAML file: %v", err)
+		return err
 	}
-	if len(config.Categories) == 0 {
-		return errors.New("categories not be empty")
+
+	// 样本文件的字段，关联文件的目标字段会追加到每一行
+	var fields []string
+	if len(inFile) > 0 {
+		rows, err := readformats.Iterate(inFile, inFormat)
+		if err != nil {
+			return fmt.Errorf("error opening input file: %v", err)
+		}
+		defer rows.Close()
+		if !rows.Next() && rows.Err() != nil {
+			return fmt.Errorf("error reading input file: %v", rows.Err())
+		}
+		fields = rows.Fields()
+		if len(targetField) > 0 && !slices.Contains(fields, targetField) {
+			fields = append(slices.Clip(fields), targetField)
+		}
 	}
 
-	// 检测input是否为空
-	if len(inFile) == 0 {
-		return errors.New("no input file")
+	filters := 0
+	for _, cate := range config.Categories {
+		filters += len(cate.Filters)
+	}
+	errs := gofofa.CheckCategories(config.Categories, fields)
+	for _, e := range errs {
+		fmt.Printf("[!] Category: %s , Filter: %d , %s\n    %v\n", e.Name, e.Index, e.Filter, e.Err)
+	}
+	fmt.Printf("[-] Checked %d filters of %d categories, %d errors\n", filters, len(config.Categories), len(errs))
+	if len(errs) > 0 {
+		return fmt.Errorf("%d broken filters in %s", len(errs), strings.Join(config.Files, ","))
+	}
+	return nil
+}
+
+func categoryTestAction(ctx *cli.Context) error {
+	// 检测无效参数
+	if len(ctx.Args().Slice()) > 0 {
+		return errors.New("invalid arguments")
 	}
 
-	_, err = gofofa.Category(ConfigFileName, inFile, gofofa.CategoryOptions{
-		Unique: unique,
-	})
+	config, err := loadCategoryConfig()
 	if err != nil {
-		return errors.New("category error: " + err.Error())
+		return err
+	}
+
+	// 数字等非字符串的值转为文本
+	var values map[string]interface{}
+	decoder := stdjson.NewDecoder(strings.NewReader(categoryRow))
+	decoder.UseNumber()
+	if err = decoder.Decode(&values); err != nil {
+		return fmt.Errorf("invalid row: %v", err)
+	}
+	row := make(map[string]string, len(values))
+	for field, value := range values {
+		switch v := value.(type) {
+		case string:
+			row[field] = v
+		case nil:
+			row[field] = ""
+		default:
+			row[field] = fmt.Sprint(v)
+		}
 	}
 
+	matches, err := gofofa.MatchCategories(config.Categories, row)
+	if err != nil {
+		return errors.New("category error: " + err.Error())
+	}
+	var matched []string
+	for _, m := range matches {
+		switch {
+		case len(m.Error) > 0:
+			fmt.Println("[!] Failed to evaluate category:", m.Name, ",", m.Error)
+		case m.Match && (!unique || len(matched) == 0):
+			fmt.Println("[+] Matches category:", m.Name, ", Filter:", m.Filter)
+			matched = append(matched, m.Name)
+		case m.Match:
+			fmt.Println("[-] Skipped category of unique:", m.Name, ", Filter:", m.Filter)
+		default:
+			fmt.Println("[-] Not matches category:", m.Name)
+		}
+	}
+	fmt.Printf("[-] Matched categories: %s\n", strings.Join(matched, ","))
 	return nil
 }
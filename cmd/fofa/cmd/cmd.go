@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/FofaInfo/GoFOFA"
@@ -13,7 +14,17 @@ var (
 )
 
 var (
-	fofaURL string // fofa url
+	fofaURL     string         // fofa url
+	configFile  string         // config file of the highest precedence
+	profileName string         // profile of config
+	fofaConfig  *gofofa.Config // config merged of all layers
+)
+
+// version of the tool, filled by main
+var (
+	Version   = "unknown"
+	Commit    = "none"
+	BuildDate = "unknown"
 )
 
 // GlobalCommands global commands
@@ -31,6 +42,20 @@ var GlobalCommands = []*cli.Command{
 	dedupCmd,
 	categoryCmd,
 	browserCmd,
+	authCmd,
+}
+
+// isHelp args has help flag of command, which no need client
+func isHelp(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--help" || arg == "-help" || arg == "-h" {
+			return true
+		}
+	}
+	return false
 }
 
 // IsValidCommand valid command name
@@ -68,11 +93,22 @@ var GlobalOptions = []cli.Flag{
 	&cli.StringFlag{
 		Name:        "fofaURL",
 		Aliases:     []string{"u"},
-		Value:       gofofa.FofaURLFromEnv(),
+		Value:       gofofa.FofaServerFromEnv(), // 默认值会显示在帮助中，不能包含key
 		Usage:       "format: <url>/?email=&key=<key>&version=<v2>",
 		Destination: &fofaURL,
 	},
 
+	&cli.StringFlag{
+		Name:        "config",
+		Usage:       "config file, over ./.fofa.yaml, ./config.yaml and $XDG_CONFIG_HOME/fofa/config.yaml",
+		Destination: &configFile,
+	},
+	&cli.StringFlag{
+		Name:        "profile",
+		Usage:       "profile of config for server, email, key, deduct mode and proxy, default is profile of config",
+		Destination: &profileName,
+	},
+
 	&cli.BoolFlag{
 		Name:  "verbose",
 		Usage: "print more information",
@@ -125,14 +161,89 @@ func BeforAction(context *cli.Context) error {
 	//	return nil
 	//}
 
-	fofaCli, err = gofofa.NewClient(gofofa.WithURL(fofaURL), gofofa.WithAccountDebug(accountDebug))
+	fofaConfig, err = gofofa.LoadConfigs(configFile)
 	if err != nil {
 		return err
 	}
+	// ./config.yaml may be a config of other tools, only category needs it
+	if fofaConfig.LegacyError != nil && context.Args().First() != categoryCmd.Name {
+		logrus.Warnf("skip config file: %v", fofaConfig.LegacyError)
+	}
 
-	if len(deductMode) > 0 {
-		fofaCli.DeductMode = gofofa.ParseDeductMode(deductMode)
+	// auth no need client, the key may not be stored yet
+	if context.Args().First() == authCmd.Name || context.Bool("help") || isHelp(context.Args().Slice()) {
+		return nil
 	}
 
+	// 优先级：fofaURL参数 > profile > 环境变量
+	options := []gofofa.ClientOption{gofofa.WithAccountDebug(accountDebug)}
+	profile, ok, err := fofaConfig.GetProfile(profileName)
+	if err != nil {
+		return err
+	}
+	if ok {
+		options = append(options, gofofa.WithProfile(profile))
+	}
+	// 默认值来自环境变量，已由NewClient读取
+	if context.IsSet("fofaURL") {
+		options = append(options, gofofa.WithURL(fofaURL))
+	}
+
+	fofaCli, err = gofofa.NewClient(options...)
+	if err != nil {
+		// 存储的凭据需要口令时提示
+		if store, e := gofofa.DefaultCredentialStore(); e == nil && len(store.Passphrase) == 0 {
+			if mode, _ := store.Mode(); mode == gofofa.CredentialModePassphrase {
+				return fmt.Errorf("%v (%v)", err, gofofa.ErrPassphraseRequired)
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+func init() {
+	for _, command := range GlobalCommands {
+		withDefaults(command)
+	}
+}
+
+// withDefaults apply default flags of config to command and its subcommands before action
+func withDefaults(command *cli.Command) {
+	before := command.Before
+	command.Before = func(ctx *cli.Context) error {
+		if err := applyDefaults(ctx); err != nil {
+			return err
+		}
+		if before != nil {
+			return before(ctx)
+		}
+		return nil
+	}
+	for _, sub := range command.Subcommands {
+		withDefaults(sub)
+	}
+}
+
+// applyDefaults set default flags of command in config which are not set in command line,
+// like dump: {fields: ip,port,host}, subcommands are named like "category check"
+func applyDefaults(ctx *cli.Context) error {
+	if fofaConfig != nil {
+		name := ctx.Command.FullName()
+		for flag, value := range fofaConfig.Defaults[name] {
+			if ctx.IsSet(flag) {
+				continue
+			}
+			if err := ctx.Set(flag, value); err != nil {
+				return fmt.Errorf("invalid default flag '%s' of %s: %v", flag, name, err)
+			}
+		}
+	}
+
+	// 命令行或默认参数指定的扣费模式，覆盖profile
+	if fofaCli != nil && ctx.IsSet("deductMode") {
+		fofaCli.DeductMode = gofofa.ParseDeductMode(deductMode)
+	}
 	return nil
 }
@@ -6,12 +6,10 @@ import (
 	"fmt"
 	"github.com/FofaInfo/GoFOFA"
 	"github.com/FofaInfo/GoFOFA/pkg/outformats"
-	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
-	"io"
 	"log"
-	"os"
 	"strings"
+	"time"
 )
 
 var (
@@ -28,7 +26,7 @@ var dumpCmd = &cli.Command{
 	Name:                   "dump",
 	Usage:                  "fofa dump data",
 	UseShortOptionHandling: true,
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:        "fields",
 			Aliases:     []string{"f"},
@@ -39,7 +37,7 @@ var dumpCmd = &cli.Command{
 		&cli.StringFlag{
 			Name:        "format",
 			Value:       "csv",
-			Usage:       "can be csv/json/xml",
+			Usage:       "can be csv/json/json-array/xml/template/geojson/nmap/masscan/targets/stix/misp/esbulk/sqlite/parquet/tsv",
 			Destination: &format,
 		},
 		&cli.BoolFlag{
@@ -60,6 +58,8 @@ var dumpCmd = &cli.Command{
 			Usage:       "queries line by line",
 			Destination: &inFile,
 		},
+		inFormatFlag(),
+		inFieldFlag("query"),
 		&cli.IntFlag{
 			Name:        "size",
 			Aliases:     []string{"s"},
@@ -99,12 +99,6 @@ var dumpCmd = &cli.Command{
 			Usage:       "batch query, can be ip/domain",
 			Destination: &batchType,
 		},
-		&cli.BoolFlag{
-			Name:        "headline",
-			Value:       false,
-			Usage:       "add headline for csv",
-			Destination: &headline,
-		},
 		&cli.StringFlag{
 			Name:        "customFields",
 			Aliases:     []string{"cf"},
@@ -112,7 +106,8 @@ var dumpCmd = &cli.Command{
 			Usage:       "use custom fields",
 			Destination: &customFields,
 		},
-	},
+		manifestFlag(),
+	}, append(outputFlags(), queryOutputFlags()...)...),
 	Action: DumpAction,
 }
 
@@ -149,8 +144,8 @@ func DumpAction(ctx *cli.Context) error {
 		queries = append(queries, query)
 	}
 	if len(inFile) > 0 {
-		// 打开文件
-		file, err := os.Open(inFile)
+		// 打开文件, 结构化文件读取query字段
+		file, err := openInFile("query")
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -190,11 +185,6 @@ func DumpAction(ctx *cli.Context) error {
 		return errors.New("fofa fields cannot be empty")
 	}
 
-	// headline只允许在format=csv的情况下使用
-	if headline && format != "csv" && len(outFile) > 0 {
-		return errors.New("headline param is only allowed if format is csv, outFile not be empty")
-	}
-
 	// batchType检验
 	if batchType != "" && batchType != "ip" && batchType != "domain" {
 		return errors.New("batchType param has to be one of ip/domain")
@@ -207,68 +197,51 @@ func DumpAction(ctx *cli.Context) error {
 	}
 
 	// gen output
-	var outTo io.Writer
-	if len(outFile) > 0 {
-		var f *os.File
-		if f, err = os.Create(outFile); err != nil {
-			return fmt.Errorf("create outFile %s failed: %w", outFile, err)
-		}
-		outTo = f
-		defer f.Close()
-	} else {
-		outTo = os.Stdout
-	}
 
 	if json {
 		format = "json"
 	}
 	// gen writer
-	var writer outformats.OutWriter
-	if hasBodyField(fields) && format == "csv" {
-		logrus.Warnln("fields contains body, so change format to json")
-		writer = outformats.NewJSONWriter(outTo, fields)
-	} else {
-		switch format {
-		case "csv":
-			writer = outformats.NewCSVWriter(outTo)
-		case "json":
-			writer = outformats.NewJSONWriter(outTo, fields)
-		case "xml":
-			writer = outformats.NewXMLWriter(outTo, fields)
-		default:
-			return fmt.Errorf("unknown format: %s", format)
-		}
+	writer, err := newOutWriter(fields)
+	if err != nil {
+		return err
 	}
 
-	if headline && format == "csv" && len(outFile) > 0 {
-		// 写入表头
-		err := writer.WriteAll([][]string{fields})
-		if err != nil {
-			return err
-		}
+	searchOptions := gofofa.SearchOptions{
+		FixUrl:    fixUrl,
+		UrlPrefix: urlPrefix,
+		Full:      full,
 	}
+	m := newManifest("dump", fields, &searchOptions)
 
 	// do search
 	for _, query := range queries {
 		log.Println("dump data of query:", query)
 
+		start := time.Now()
 		fetchedSize := 0
+		querySize := 0
+		options := searchOptions
+		options.OnTotal = func(total int) {
+			querySize = total
+			outformats.SetMeta(writer, query, total)
+		}
 		err := fofaCli.DumpSearch(query, size, batchSize, fields, func(res [][]string, allSize int) (err error) {
 			fetchedSize += len(res)
 			log.Printf("size: %d/%d, %.2f%%", fetchedSize, allSize, 100*float32(fetchedSize)/float32(allSize))
 			// output
 			err = writer.WriteAll(res)
 			return err
-		}, gofofa.SearchOptions{
-			FixUrl:    fixUrl,
-			UrlPrefix: urlPrefix,
-			Full:      full,
-		})
+		}, options)
 		if err != nil {
 			log.Println("fetch error:", err)
 			//return err
 		}
+		m.addQuery(query, querySize, fetchedSize, start)
 	}
 
-	return nil
+	if err = outformats.Close(writer); err != nil {
+		return err
+	}
+	return m.write(outformats.Files(writer))
 }
@@ -1,23 +1,33 @@
 package cmd
 
 import (
-	"encoding/csv"
 	"errors"
 	"fmt"
+	"github.com/FofaInfo/GoFOFA/pkg/dedup"
+	"github.com/FofaInfo/GoFOFA/pkg/outformats"
+	"github.com/FofaInfo/GoFOFA/pkg/readformats"
 	"github.com/urfave/cli/v2"
-	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 )
 
 var (
 	dedupString string
+	dedupKeep   string // which record to keep among duplicates
+	dedupPrefer string // prefer rule like type=subdomain>service
+	dedupTime   string // time field of newest
+	dedupMemory string // max bytes of records in memory
+	dedupTemp   string // dir of temp files
+	simFields   string // fields compared for near-duplicates
 )
 
 var dedupCmd = &cli.Command{
 	Name:                   "dedup",
 	Usage:                  "remove duplicate tool",
 	UseShortOptionHandling: true,
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:        "dedup",
 			Aliases:     []string{"d"},
@@ -27,100 +37,260 @@ var dedupCmd = &cli.Command{
 		&cli.StringFlag{
 			Name:        "inFile",
 			Aliases:     []string{"i"},
-			Usage:       "input duplicate file",
+			Usage:       "input duplicate file, can be csv/tsv/json/xml/xlsx",
 			Destination: &inFile,
 		},
+		inFormatFlag(),
 		&cli.StringFlag{
 			Name:        "outFile",
 			Aliases:     []string{"o"},
 			Value:       "duplicate.csv",
-			Usage:       "write csv file",
+			Usage:       "write csv file, or json lines if extension is .json/.jsonl/.ndjson",
 			Destination: &outFile,
 		},
-	},
+		&cli.StringFlag{
+			Name:        "format",
+			Usage:       "format of outFile, can be csv/tsv/json, detected by extension of outFile if empty",
+			Destination: &format,
+		},
+		&cli.StringFlag{
+			Name:        "keep",
+			Usage:       "which record to keep among duplicates, can be " + strings.Join(dedup.Keeps, "/") + ", default is first, or prefer if prefer is set",
+			Destination: &dedupKeep,
+		},
+		&cli.StringFlag{
+			Name:        "prefer",
+			Usage:       "prefer rule of keep prefer, like type=subdomain>service",
+			Destination: &dedupPrefer,
+		},
+		&cli.StringFlag{
+			Name:        "timeField",
+			Value:       dedup.DefaultTimeField,
+			Usage:       "time field of keep newest",
+			Destination: &dedupTime,
+		},
+		&cli.StringFlag{
+			Name:        "memory",
+			Value:       "64M",
+			Usage:       "max bytes of records in memory, larger files are sorted on disk",
+			Destination: &dedupMemory,
+		},
+		&cli.StringFlag{
+			Name:        "tempDir",
+			Usage:       "dir of temp files, default is the system temp dir",
+			Destination: &dedupTemp,
+		},
+		similarFlag(),
+		similarThresholdFlag(),
+		&cli.StringFlag{
+			Name:        "similarFields",
+			Usage:       "fields compared for near-duplicates, default is " + strings.Join(dedup.SimilarFields, ",") + " in inFile",
+			Destination: &simFields,
+		},
+		deWildcardFlag(),
+		wildcardMinFlag(),
+	}, csvFlags()...),
 
 	Action: deduplicateAction,
 }
 
-func isCSV(filename string) bool {
-	return strings.HasSuffix(strings.ToLower(filename), ".csv")
+// dedupFormat format of output file, json lines for json extensions, csv by default
+func dedupFormat() (string, error) {
+	switch format {
+	case "csv", "tsv", "json":
+		return format, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported format of dedup: %s", format)
+	}
+	switch strings.ToLower(filepath.Ext(outFile)) {
+	case ".json", ".jsonl", ".ndjson":
+		return "json", nil
+	case ".tsv":
+		return "tsv", nil
+	}
+	return "csv", nil
 }
 
-func readCSV(filePath string) ([][]string, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
+// dedupOptions options of deduper from flags
+func dedupOptions() (dedup.Options, error) {
+	opts := dedup.Options{
+		Keep:      dedupKeep,
+		TimeField: dedupTime,
+		TempDir:   dedupTemp,
 	}
-	defer file.Close()
-
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+	if len(dedupPrefer) > 0 {
+		rule, err := dedup.ParseRule(dedupPrefer)
+		if err != nil {
+			return opts, err
+		}
+		opts.Prefer = rule
+		if len(opts.Keep) == 0 {
+			opts.Keep = dedup.KeepPrefer
+		}
+	} else if opts.Keep == dedup.KeepPrefer {
+		return opts, errors.New("keep prefer needs prefer rule")
+	}
+	memory, err := parseBytes(dedupMemory)
 	if err != nil {
-		return nil, err
+		return opts, err
 	}
-	return records, nil
+	opts.MemoryLimit = memory
+	return opts, nil
 }
 
-func writeCSV(filePath string, records [][]string) error {
-	file, err := os.Create(filePath)
+// deduplicates write records of rows to writer, records with the same values of fields are written once.
+// Records are sorted on disk if they exceed the memory limit.
+func deduplicates(rows *readformats.Iterator, fields []string, opts dedup.Options, writer outformats.OutWriter) (int, error) {
+	d, err := dedup.New(rows.Fields(), fields, opts)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	defer d.Close()
 
-	for _, record := range records {
-		if err = writer.Write(record); err != nil {
-			return err
+	for rows.Next() {
+		if err = d.Add(slices.Clone(rows.Record())); err != nil {
+			return 0, err
 		}
 	}
-	return nil
-}
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	if d.Total() == 0 {
+		return 0, errors.New("input file is empty")
+	}
 
-// indexOf 查找字段在表头中的索引
-func indexOf(headers []string, field string) int {
-	for i, header := range headers {
-		if header == field {
-			return i
-		}
+	if err = writer.WriteHeader(rows.Fields()); err != nil {
+		return 0, err
 	}
-	return -1
+	err = d.Each(func(record []string) error {
+		return writer.WriteAll([][]string{record})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return d.Unique(), nil
 }
 
-func deduplicates(records [][]string, fields []string) ([][]string, error) {
-	if len(records) < 2 {
-		return nil, errors.New("deduplicate failed: CSV file is empty")
+// similarDedup write the first record of each cluster of near-duplicates of inFile to outFile of format, with size of cluster.
+// inFile is read twice, the first time to cluster records, so that only clusters are kept in memory.
+func similarDedup(opts dedup.SimilarOptions, format string) (clusters int, total int, err error) {
+	rows, err := iterateInFile(inFormat)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+	clusterer, err := dedup.NewClusterer(rows.Fields(), opts)
+	if err != nil {
+		return 0, 0, err
+	}
+	var reps []int // index of record of each cluster
+	for rows.Next() {
+		if _, isNew := clusterer.Add(slices.Clone(rows.Record())); isNew {
+			reps = append(reps, rows.Count()-1)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return 0, 0, err
+	}
+	if rows.Count() == 0 {
+		return 0, 0, errors.New("input file is empty")
 	}
 
-	// 获取字段索引
-	fieldIndexes := make([]int, 0)
-	for _, field := range fields {
-		index := indexOf(fields, field)
-		if index == -1 {
-			return nil, fmt.Errorf("field '%s' not found in headers", field)
+	// skipped rows are the same as the first time, which are warned already
+	second, err := readformats.Iterate(inFile, inFormat)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer second.Close()
+	fields := append(slices.Clip(second.Fields()), dedup.ClusterSizeField)
+	writer, err := newFileWriter(format, outFile, fields)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() {
+		if e := outformats.Close(writer); e != nil && err == nil {
+			err = e
 		}
-		fieldIndexes = append(fieldIndexes, index)
+	}()
+	if err = writer.WriteHeader(fields); err != nil {
+		return 0, 0, err
 	}
+	id := 0
+	for id < len(reps) && second.Next() {
+		if second.Count()-1 != reps[id] {
+			continue
+		}
+		record := append(slices.Clone(second.Record()), strconv.Itoa(clusterer.Size(id)))
+		if err = writer.WriteAll([][]string{record}); err != nil {
+			return 0, 0, err
+		}
+		id++
+	}
+	if err = second.Err(); err != nil {
+		return 0, 0, err
+	}
+	return len(reps), rows.Count(), nil
+}
 
-	// 去重逻辑
-	seen := make(map[string]bool)
-	uniqueRows := [][]string{records[0]}
+// wildcardDedup write records of inFile to outFile of format, subdomains of each wildcard parent domain
+// are collapsed into representatives, with is_wildcard and count of subdomains they represent.
+// inFile is read twice, the first time to detect wildcard, so that only subdomains are kept in memory.
+func wildcardDedup(opts dedup.WildcardOptions, format string) (kept int, total int, parents []string, err error) {
+	rows, err := iterateInFile(inFormat)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	defer rows.Close()
+	detector, err := dedup.NewWildcardDetector(rows.Fields(), opts)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	for rows.Next() {
+		detector.Add(slices.Clone(rows.Record()))
+	}
+	if err = rows.Err(); err != nil {
+		return 0, 0, nil, err
+	}
+	if rows.Count() == 0 {
+		return 0, 0, nil, errors.New("input file is empty")
+	}
+	parents = detector.Detect()
 
-	for _, row := range records[1:] {
-		keyParts := make([]string, len(fields))
-		for i, idx := range fieldIndexes {
-			keyParts[i] = row[idx]
+	// skipped rows are the same as the first time, which are warned already
+	second, err := readformats.Iterate(inFile, inFormat)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	defer second.Close()
+	fields := append(slices.Clip(second.Fields()), dedup.IsWildcardField, dedup.WildcardCountField)
+	writer, err := newFileWriter(format, outFile, fields)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	defer func() {
+		if e := outformats.Close(writer); e != nil && err == nil {
+			err = e
+		}
+	}()
+	if err = writer.WriteHeader(fields); err != nil {
+		return 0, 0, nil, err
+	}
+	for second.Next() {
+		keep, count := detector.Check(second.Record())
+		if !keep {
+			continue
 		}
-		key := strings.Join(keyParts, "|")
-		if !seen[key] {
-			seen[key] = true
-			uniqueRows = append(uniqueRows, row)
+		record := append(slices.Clone(second.Record()), strconv.FormatBool(count > 0), strconv.Itoa(max(count, 1)))
+		if err = writer.WriteAll([][]string{record}); err != nil {
+			return 0, 0, nil, err
 		}
+		kept++
 	}
-
-	return uniqueRows, nil
+	if err = second.Err(); err != nil {
+		return 0, 0, nil, err
+	}
+	return kept, rows.Count(), parents, nil
 }
 
 func deduplicateAction(ctx *cli.Context) error {
@@ -130,30 +300,75 @@ func deduplicateAction(ctx *cli.Context) error {
 	}
 
 	duplicate := strings.Split(dedupString, ",")
-	if len(duplicate) == 0 || len(inFile) == 0 {
-		return errors.New("flag needs arguments: -d field -i target.csv")
+	modes := 0
+	for _, set := range []bool{len(dedupString) > 0, len(similar) > 0, deWildcard > 0} {
+		if set {
+			modes++
+		}
+	}
+	if modes == 0 || len(inFile) == 0 {
+		return errors.New("flag needs arguments: -d field -i target.csv, -similar simhash -i target.csv, or -deWildcard 1 -i target.csv")
+	}
+	if modes > 1 {
+		return errors.New("dedup, similar and deWildcard cannot be used together")
+	}
+	opts, err := dedupOptions()
+	if err != nil {
+		return err
+	}
+	outFormat, err := dedupFormat()
+	if err != nil {
+		return err
+	}
+
+	if len(similar) > 0 {
+		simOpts := dedup.SimilarOptions{
+			Method:    similar,
+			Threshold: similarThreshold,
+		}
+		if len(simFields) > 0 {
+			simOpts.Fields = strings.Split(simFields, ",")
+		}
+		clusters, total, err := similarDedup(simOpts, outFormat)
+		if err != nil {
+			return errors.New("deduplicates failed: " + err.Error())
+		}
+		fmt.Printf("The deduplicated file has been created: %s, %d clusters of %d records\n", outFile, clusters, total)
+		return nil
 	}
 
-	if !isCSV(inFile) {
-		return errors.New("invalid file type, please input a csv file type")
+	if deWildcard > 0 {
+		kept, total, parents, err := wildcardDedup(dedup.WildcardOptions{MinSubdomains: wildcardMin, Keep: deWildcard}, outFormat)
+		if err != nil {
+			return errors.New("deduplicates failed: " + err.Error())
+		}
+		if len(parents) > 0 {
+			fmt.Println("Wildcard parent domains:", strings.Join(parents, ","))
+		}
+		fmt.Printf("The deduplicated file has been created: %s, %d of %d records\n", outFile, kept, total)
+		return nil
 	}
 
-	records, err := readCSV(inFile)
+	rows, err := iterateInFile(inFormat)
 	if err != nil {
-		return errors.New("read csv file failed: " + err.Error())
+		return errors.New("read input file failed: " + err.Error())
 	}
+	defer rows.Close()
 
-	uniqueRecords, err := deduplicates(records, duplicate)
+	writer, err := newFileWriter(outFormat, outFile, rows.Fields())
 	if err != nil {
-		return errors.New("deduplicates failed: " + err.Error())
+		return errors.New("write output file failed: " + err.Error())
 	}
-
-	err = writeCSV(outFile, uniqueRecords)
+	unique, err := deduplicates(rows, duplicate, opts, writer)
 	if err != nil {
-		return errors.New("write csv file failed: " + err.Error())
+		outformats.Close(writer)
+		return errors.New("deduplicates failed: " + err.Error())
+	}
+	if err = outformats.Close(writer); err != nil {
+		return errors.New("write output file failed: " + err.Error())
 	}
 
-	fmt.Println("The deduplicated CSV file has been created:", outFile)
+	fmt.Printf("The deduplicated file has been created: %s, %d of %d records\n", outFile, unique, rows.Count())
 
 	return nil
 }
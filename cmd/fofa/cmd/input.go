@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/FofaInfo/GoFOFA/pkg/readformats"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	inFormat string // format of inFile
+	inField  string // field of structured inFile used as input
+)
+
+// inFormatFlag flag of format of inFile
+func inFormatFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:        "inFormat",
+		Usage:       "format of inFile, can be " + strings.Join(readformats.Formats, "/") + ", detected by extension or content if empty",
+		Destination: &inFormat,
+	}
+}
+
+// inFieldFlag flag of field used as input of structured inFile, candidates are the default fields
+func inFieldFlag(candidates ...string) cli.Flag {
+	return &cli.StringFlag{
+		Name:        "inField",
+		Usage:       "field used as input if inFile is csv/tsv/json/xml/xlsx, default is the first of " + strings.Join(candidates, "/") + " or the first column",
+		Destination: &inField,
+	}
+}
+
+// inputIndex index of inField, or the first existing field of candidates, or 0
+func inputIndex(fields []string, candidates []string) (int, error) {
+	if len(inField) > 0 {
+		index := slices.Index(fields, inField)
+		if index == -1 {
+			return -1, fmt.Errorf("inField %s not found in fields of inFile: %s", inField, strings.Join(fields, ","))
+		}
+		return index, nil
+	}
+	for _, field := range candidates {
+		if index := slices.Index(fields, field); index != -1 {
+			return index, nil
+		}
+	}
+	return 0, nil
+}
+
+// warnSkippedRow warn row of file which is skipped, as it can't be aligned with the header
+func warnSkippedRow(file string, err *readformats.RowError) {
+	logrus.Warnf("skip row of %s: %v", file, err)
+}
+
+// iterateInFile iterator of inFile of format, skipped rows are warned
+func iterateInFile(format string) (*readformats.Iterator, error) {
+	rows, err := readformats.Iterate(inFile, format)
+	if err != nil {
+		return nil, err
+	}
+	rows.OnSkip = func(err *readformats.RowError) {
+		warnSkippedRow(inFile, err)
+	}
+	return rows, nil
+}
+
+// openInFile lines of inFile, plain text file is read as is,
+// values of the input field are read as lines from structured file like csv/json/xlsx.
+// csv/tsv without a header, whose first row has neither inField nor a fofa field, is read as plain text.
+func openInFile(candidates ...string) (io.ReadCloser, error) {
+	format := inFormat
+	if len(format) == 0 {
+		var err error
+		if format, err = readformats.DetectFormat(inFile); err != nil {
+			return nil, err
+		}
+	}
+	if len(format) == 0 {
+		return os.Open(inFile)
+	}
+
+	rows, err := iterateInFile(format)
+	if err != nil {
+		return nil, err
+	}
+	if (format == readformats.FormatCSV || format == readformats.FormatTSV) &&
+		!readformats.IsHeader(rows.Fields(), append([]string{inField}, candidates...)...) {
+		rows.Close()
+		return os.Open(inFile)
+	}
+	index, err := inputIndex(rows.Fields(), candidates)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer rows.Close()
+		for rows.Next() {
+			value := strings.TrimSpace(strings.ReplaceAll(rows.Record()[index], "\n", " "))
+			if len(value) == 0 {
+				continue
+			}
+			if _, err := io.WriteString(pw, value+"\n"); err != nil {
+				// closed by reader
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			pw.CloseWithError(fmt.Errorf("read %s failed: %w", inFile, err))
+			return
+		}
+		pw.Close()
+	}()
+	return pr, nil
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	stdjson "encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/FofaInfo/GoFOFA"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	manifestFile string      // write provenance manifest of output to file
+	stdoutHash   *hashWriter // hash of stdout output, used by manifest
+)
+
+// manifestFlag flag of provenance manifest, shared by search, dump and stats
+func manifestFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:        "manifest",
+		Usage:       "write provenance manifest of the export to json file, with query, options, account and sha256 of outputs",
+		Destination: &manifestFile,
+	}
+}
+
+// hashWriter sha256 and size of written data
+type hashWriter struct {
+	h hash.Hash
+	n int64
+}
+
+func (w *hashWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return w.h.Write(p)
+}
+
+func newHashWriter() *hashWriter {
+	return &hashWriter{h: sha256.New()}
+}
+
+// stdoutWriter stdout, which is also hashed if manifest is set
+func stdoutWriter() io.Writer {
+	if len(manifestFile) == 0 {
+		return os.Stdout
+	}
+	if stdoutHash == nil {
+		stdoutHash = newHashWriter()
+	}
+	return io.MultiWriter(os.Stdout, stdoutHash)
+}
+
+// manifestTool version of fofa tool
+type manifestTool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Date    string `json:"date"`
+}
+
+// manifestAccount account of client, key is redacted
+type manifestAccount struct {
+	Email    string          `json:"email"`
+	Key      string          `json:"key"`
+	IsVIP    bool            `json:"isvip"`
+	VIPLevel gofofa.VipLevel `json:"vip_level"`
+}
+
+// manifestQuery provenance of each query
+type manifestQuery struct {
+	Query     string    `json:"query"`
+	Size      int       `json:"size"` // total size reported by server
+	Rows      int       `json:"rows"` // rows written
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// manifestOutput output file and its sha256
+type manifestOutput struct {
+	Path   string `json:"path"` // stdout for standard output
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// manifest provenance of an export, written as sidecar json by --manifest
+type manifest struct {
+	Tool       manifestTool          `json:"tool"`
+	Command    string                `json:"command"`
+	Server     string                `json:"server"`
+	APIVersion string                `json:"api_version"`
+	Account    manifestAccount       `json:"account"`
+	Fields     []string              `json:"fields"`
+	Size       int                   `json:"size"` // size param of command
+	Format     string                `json:"format,omitempty"`
+	Options    *gofofa.SearchOptions `json:"options,omitempty"`
+	StartTime  time.Time             `json:"start_time"`
+	EndTime    time.Time             `json:"end_time"`
+	Total      int                   `json:"total"` // sum of total size reported by server
+	Rows       int                   `json:"rows"`  // sum of rows written
+	Queries    []manifestQuery       `json:"queries"`
+	Outputs    []manifestOutput      `json:"outputs"`
+
+	lock sync.Mutex
+}
+
+// newManifest generate manifest of command, nil if manifest is not set
+func newManifest(command string, fields []string, options *gofofa.SearchOptions) *manifest {
+	if len(manifestFile) == 0 {
+		return nil
+	}
+	m := &manifest{
+		Tool: manifestTool{
+			Name:    "fofa",
+			Version: Version,
+			Commit:  Commit,
+			Date:    BuildDate,
+		},
+		Command:   command,
+		Fields:    fields,
+		Size:      size,
+		Format:    format,
+		Options:   options,
+		StartTime: time.Now(),
+	}
+	if fofaCli != nil {
+		m.Server = fofaCli.Server
+		m.APIVersion = fofaCli.APIVersion
+		m.Account = manifestAccount{
+			Email:    fofaCli.Email,
+			IsVIP:    fofaCli.Account.IsVIP,
+			VIPLevel: fofaCli.Account.VIPLevel,
+		}
+		if len(fofaCli.Key) > 0 {
+			m.Account.Key = "<key>"
+		}
+	}
+	return m
+}
+
+// addQuery add provenance of query started at start
+func (m *manifest) addQuery(query string, total int, rows int, start time.Time) {
+	if m == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.Queries = append(m.Queries, manifestQuery{
+		Query:     query,
+		Size:      total,
+		Rows:      rows,
+		StartTime: start,
+		EndTime:   time.Now(),
+	})
+	m.Total += total
+	m.Rows += rows
+}
+
+// fileHash sha256 and size of file
+func fileHash(path string) (manifestOutput, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return manifestOutput{}, err
+	}
+	defer f.Close()
+	w := newHashWriter()
+	if _, err = io.Copy(w, f); err != nil {
+		return manifestOutput{}, err
+	}
+	return manifestOutput{Path: path, Bytes: w.n, SHA256: hex.EncodeToString(w.h.Sum(nil))}, nil
+}
+
+// write the manifest with sha256 of files and stdout, should be called after outputs are closed
+func (m *manifest) write(files []string) error {
+	if m == nil {
+		return nil
+	}
+	m.EndTime = time.Now()
+	for i, file := range files {
+		// 如targets格式在outDir中每个查询都追加到相同的文件
+		if slices.Contains(files[:i], file) {
+			continue
+		}
+		output, err := fileHash(file)
+		if err != nil {
+			return fmt.Errorf("hash output %s failed: %w", file, err)
+		}
+		m.Outputs = append(m.Outputs, output)
+	}
+	if stdoutHash != nil && stdoutHash.n > 0 {
+		m.Outputs = append(m.Outputs, manifestOutput{
+			Path:   "stdout",
+			Bytes:  stdoutHash.n,
+			SHA256: hex.EncodeToString(stdoutHash.h.Sum(nil)),
+		})
+	}
+
+	var b bytes.Buffer
+	enc := stdjson.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return err
+	}
+	if err := os.WriteFile(manifestFile, b.Bytes(), 0644); err != nil {
+		return fmt.Errorf("write manifest %s failed: %w", manifestFile, err)
+	}
+	return nil
+}
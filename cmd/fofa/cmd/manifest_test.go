@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	stdjson "encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/FofaInfo/GoFOFA/pkg/outformats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManifest_Targets(t *testing.T) {
+	dir := t.TempDir()
+	manifestFile = filepath.Join(dir, "manifest.json")
+	format = "targets"
+	targetsDir = filepath.Join(dir, "targets")
+	outDir = filepath.Join(dir, "out")
+	outName = "index"
+	t.Cleanup(func() {
+		manifestFile, format, targetsDir, outDir, outName = "", "", "", "", ""
+		targetsOpen = false
+		stdoutHash = nil
+	})
+
+	fields := []string{"ip", "port", "protocol"}
+	m := newManifest("search", fields, nil)
+	writer, err := newOutWriter(fields)
+	assert.Nil(t, err)
+	outformats.SetMeta(writer, "port=80", 2)
+	assert.Nil(t, writer.WriteAll([][]string{{"1.1.1.1", "80", "http"}, {"1.1.1.2", "22", "ssh"}}))
+	outformats.SetMeta(writer, "port=22", 2)
+	assert.Nil(t, writer.WriteAll([][]string{{"1.1.1.3", "80", "http"}, {"1.1.1.4", "22", "ssh"}}))
+	assert.Nil(t, outformats.Close(writer))
+	assert.Nil(t, m.write(outformats.Files(writer)))
+
+	// 输出为targets文件，每个文件只记录一次
+	data, err := os.ReadFile(manifestFile)
+	assert.Nil(t, err)
+	var got struct {
+		Outputs []manifestOutput `json:"outputs"`
+	}
+	assert.Nil(t, stdjson.Unmarshal(data, &got))
+	var paths []string
+	for _, output := range got.Outputs {
+		paths = append(paths, output.Path)
+		want, err := fileHash(output.Path)
+		assert.Nil(t, err)
+		assert.Equal(t, want, output)
+	}
+	assert.ElementsMatch(t, []string{
+		filepath.Join(targetsDir, "http_targets.txt"),
+		filepath.Join(targetsDir, "ssh_targets.txt"),
+	}, paths)
+	d, err := os.ReadFile(filepath.Join(targetsDir, "http_targets.txt"))
+	assert.Nil(t, err)
+	assert.Equal(t, "http://1.1.1.1:80\nhttp://1.1.1.3:80\n", string(d))
+	entries, _ := os.ReadDir(outDir)
+	assert.Empty(t, entries)
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 	"math/rand"
-	"os"
 	"strings"
 	"time"
 )
@@ -22,7 +21,7 @@ var randomCmd = &cli.Command{
 	Name:                   "random",
 	Usage:                  "fofa random data generator",
 	UseShortOptionHandling: true,
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:        "fields",
 			Aliases:     []string{"f"},
@@ -33,7 +32,7 @@ var randomCmd = &cli.Command{
 		&cli.StringFlag{
 			Name:        "format",
 			Value:       "json",
-			Usage:       "can be csv/json/xml",
+			Usage:       "can be csv/json/json-array/xml/template/geojson/nmap/masscan/targets/stix/misp/esbulk/sqlite/parquet/tsv",
 			Destination: &format,
 		},
 		&cli.IntFlag{
@@ -74,7 +73,7 @@ var randomCmd = &cli.Command{
 			Usage:       "use custom fields",
 			Destination: &customFields,
 		},
-	},
+	}, outputFlags()...),
 	Action: randomAction,
 }
 
@@ -108,22 +107,9 @@ func randomAction(ctx *cli.Context) error {
 	}
 
 	// gen writer
-	outTo := os.Stdout
-	var writer outformats.OutWriter
-	if hasBodyField(fields) && format == "csv" {
-		logrus.Warnln("fields contains body, so change format to json")
-		writer = outformats.NewJSONWriter(outTo, fields)
-	} else {
-		switch format {
-		case "csv":
-			writer = outformats.NewCSVWriter(outTo)
-		case "json":
-			writer = outformats.NewJSONWriter(outTo, fields)
-		case "xml":
-			writer = outformats.NewXMLWriter(outTo, fields)
-		default:
-			return fmt.Errorf("unknown format: %s", format)
-		}
+	writer, err := newOutWriter(fields)
+	if err != nil {
+		return err
 	}
 
 	// do search
@@ -164,5 +150,5 @@ func randomAction(ctx *cli.Context) error {
 		}
 	}
 
-	return nil
+	return outformats.Close(writer)
 }
@@ -6,8 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"github.com/FofaInfo/GoFOFA"
+	"github.com/FofaInfo/GoFOFA/pkg/dedup"
 	"github.com/FofaInfo/GoFOFA/pkg/outformats"
-	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/time/rate"
 	"io"
@@ -15,6 +15,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 )
 
 var (
@@ -34,11 +35,14 @@ var (
 	ratePerSecond int    // fofa request per second
 	template      string // template in pipeline mode
 	checkActive   int    // probe website is existed, add isActive field
-	deWildcard    int    // number of wildcard domains retained
+	deWildcard    int    // number of subdomains retained of each wildcard parent domain
 	filter        string // filter data by rules
 	dedupHost     bool   // deduplicate by host
-	headline      bool   // add headline for csv
 	customFields  string // use custom fields
+
+	similar          string  // cluster near-duplicates by simhash/minhash
+	similarThreshold float64 // similarity threshold of near-duplicates
+	wildcardMin      int     // count of subdomains sharing the same signature to detect wildcard
 )
 
 // search subcommand
@@ -46,7 +50,7 @@ var searchCmd = &cli.Command{
 	Name:                   "search",
 	Usage:                  "fofa host search",
 	UseShortOptionHandling: true,
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:        "fields",
 			Aliases:     []string{"f"},
@@ -57,7 +61,7 @@ var searchCmd = &cli.Command{
 		&cli.StringFlag{
 			Name:        "format",
 			Value:       "csv",
-			Usage:       "can be csv/json/xml",
+			Usage:       "can be csv/json/json-array/xml/template/geojson/nmap/masscan/targets/stix/misp/esbulk/sqlite/parquet/tsv",
 			Destination: &format,
 		},
 		&cli.StringFlag{
@@ -127,22 +131,20 @@ var searchCmd = &cli.Command{
 			Usage:       "input file to build template if not use pipeline mode",
 			Destination: &inFile,
 		},
+		inFormatFlag(),
+		inFieldFlag("ip", "host", "domain"),
 		&cli.IntFlag{
 			Name:        "checkActive",
 			Value:       -1,
 			Usage:       "probe website is existed, add isActive field",
 			Destination: &checkActive,
 		},
-		&cli.IntFlag{
-			Name:        "deWildcard",
-			Value:       -1,
-			Usage:       "number of wildcard domains retained",
-			Destination: &deWildcard,
-		},
+		deWildcardFlag(),
+		wildcardMinFlag(),
 		&cli.StringFlag{
 			Name:        "filter",
 			Value:       "",
-			Usage:       "filter data by rules",
+			Usage:       "filter data by rules, like int(port) < 100 && inCIDR(ip, \"10.0.0.0/8\")",
 			Destination: &filter,
 		},
 		&cli.BoolFlag{
@@ -151,12 +153,8 @@ var searchCmd = &cli.Command{
 			Usage:       "deduplicate by host",
 			Destination: &dedupHost,
 		},
-		&cli.BoolFlag{
-			Name:        "headline",
-			Value:       false,
-			Usage:       "add headline for csv",
-			Destination: &headline,
-		},
+		similarFlag(),
+		similarThresholdFlag(),
 		&cli.StringFlag{
 			Name:        "customFields",
 			Aliases:     []string{"cf"},
@@ -164,24 +162,55 @@ var searchCmd = &cli.Command{
 			Usage:       "use custom fields",
 			Destination: &customFields,
 		},
-	},
+		manifestFlag(),
+	}, append(outputFlags(), queryOutputFlags()...)...),
 	Action: SearchAction,
 }
 
-func getCustomFields(fieldName string) (string, error) {
-	config, err := gofofa.LoadConfig(ConfigFileName)
-	if err != nil {
-		return "", err
+// similarFlag flag of near-duplicate clustering method
+func similarFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:        "similar",
+		Usage:       "keep one record of each cluster of near-duplicates by " + strings.Join(dedup.SimilarMethods, "/") + " of body/title/header, add " + dedup.ClusterSizeField + " field",
+		Destination: &similar,
 	}
-	if config == nil {
-		return "", errors.New("config is nil")
+}
+
+// deWildcardFlag flag of wildcard dns detection
+func deWildcardFlag() cli.Flag {
+	return &cli.IntFlag{
+		Name:        "deWildcard",
+		Value:       -1,
+		Usage:       "detect wildcard dns and retain number of subdomains of each wildcard parent domain, add " + dedup.IsWildcardField + " and " + dedup.WildcardCountField + " fields",
+		Destination: &deWildcard,
 	}
-	for _, field := range config.CustomFields {
-		if field.Name == fieldName {
-			return field.Fields, nil
-		}
+}
+
+// wildcardMinFlag flag of count of subdomains to detect wildcard dns
+func wildcardMinFlag() cli.Flag {
+	return &cli.IntFlag{
+		Name:        "wildcardMin",
+		Value:       dedup.DefaultWildcardMin,
+		Usage:       "count of subdomains sharing the same ip set, title and body hash to detect wildcard",
+		Destination: &wildcardMin,
+	}
+}
+
+// similarThresholdFlag flag of similarity threshold of near-duplicates
+func similarThresholdFlag() cli.Flag {
+	return &cli.Float64Flag{
+		Name:        "similarThreshold",
+		Value:       dedup.DefaultSimilarThreshold,
+		Usage:       "similarity threshold of near-duplicates, in (0, 1]",
+		Destination: &similarThreshold,
+	}
+}
+
+func getCustomFields(fieldName string) (string, error) {
+	if fofaConfig == nil {
+		return "", errors.New("config is nil")
 	}
-	return "", errors.New("field not found")
+	return fofaConfig.GetCustomFields(fieldName)
 }
 
 func fieldIndex(fields []string, fieldName string) int {
@@ -273,11 +302,6 @@ func SearchAction(ctx *cli.Context) error {
 		return errors.New("fofa fields cannot be empty")
 	}
 
-	// headline只允许在format=csv的情况下使用
-	if headline && format != "csv" && len(outFile) > 0 {
-		return errors.New("headline param is only allowed if format is csv, outFile not be empty")
-	}
-
 	// deWildcard不能为0
 	if deWildcard == 0 {
 		return errors.New("deWildcard param cannot be zero")
@@ -289,63 +313,50 @@ func SearchAction(ctx *cli.Context) error {
 	}
 
 	// gen output
-	var outTo io.Writer
-	if len(outFile) > 0 {
-		var f *os.File
-		if f, err = os.Create(outFile); err != nil {
-			return fmt.Errorf("create outFile %s failed: %w", outFile, err)
-		}
-		outTo = f
-		defer f.Close()
-	} else {
-		outTo = os.Stdout
-	}
 
 	// gen writer
-	var writer outformats.OutWriter
 	var headFields = fields
 	if checkActive > 0 {
 		headFields = append(headFields, "isActive")
 	}
-	if hasBodyField(fields) && format == "csv" {
-		logrus.Warnln("fields contains body, so change format to json")
-		writer = outformats.NewJSONWriter(outTo, headFields)
-	} else {
-		switch format {
-		case "csv":
-			writer = outformats.NewCSVWriter(outTo)
-		case "json":
-			writer = outformats.NewJSONWriter(outTo, headFields)
-		case "xml":
-			writer = outformats.NewXMLWriter(outTo, headFields)
-		default:
-			return fmt.Errorf("unknown format: %s", format)
-		}
+	if len(similar) > 0 {
+		headFields = append(headFields, dedup.ClusterSizeField)
 	}
-
-	if headline && format == "csv" && len(outFile) > 0 {
-		// 写入表头
-		err := writer.WriteAll([][]string{headFields})
-		if err != nil {
-			return err
-		}
+	if deWildcard > 0 {
+		headFields = append(headFields, dedup.IsWildcardField, dedup.WildcardCountField)
+	}
+	writer, err := newOutWriter(headFields)
+	if err != nil {
+		return err
 	}
 
 	var locker sync.Mutex
 
+	searchOptions := gofofa.SearchOptions{
+		FixUrl:           fixUrl,
+		UrlPrefix:        urlPrefix,
+		Full:             full,
+		UniqByIP:         uniqByIP,
+		CheckActive:      checkActive,
+		DeWildcard:       deWildcard,
+		WildcardMin:      wildcardMin,
+		Filter:           filter,
+		DedupHost:        dedupHost,
+		Similar:          similar,
+		SimilarThreshold: similarThreshold,
+	}
+	m := newManifest("search", fields, &searchOptions)
+
 	writeQuery := func(query string) error {
 		log.Println("query fofa of:", query)
 		// do search
-		res, err := fofaCli.HostSearch(query, size, fields, gofofa.SearchOptions{
-			FixUrl:      fixUrl,
-			UrlPrefix:   urlPrefix,
-			Full:        full,
-			UniqByIP:    uniqByIP,
-			CheckActive: checkActive,
-			DeWildcard:  deWildcard,
-			Filter:      filter,
-			DedupHost:   dedupHost,
-		})
+		start := time.Now()
+		total := 0
+		options := searchOptions
+		options.OnTotal = func(t int) {
+			total = t
+		}
+		res, err := fofaCli.HostSearch(query, size, fields, options)
 		if err != nil {
 			return err
 		}
@@ -353,20 +364,24 @@ func SearchAction(ctx *cli.Context) error {
 		// output
 		locker.Lock()
 		defer locker.Unlock()
+		outformats.SetMeta(writer, query, total)
 		if err = writer.WriteAll(res); err != nil {
 			return err
 		}
 		writer.Flush()
+		m.addQuery(query, total, len(res), start)
 
 		return nil
 	}
 
 	if query != "" {
-		return writeQuery(query)
+		if err = writeQuery(query); err != nil {
+			return err
+		}
 	} else {
 		var inf io.Reader
 		if inFile != "" {
-			f, err := os.Open(inFile)
+			f, err := openInFile("ip", "host", "domain")
 			if err != nil {
 				return err
 			}
@@ -378,5 +393,8 @@ func SearchAction(ctx *cli.Context) error {
 		pipelineProcess(writeQuery, inf)
 	}
 
-	return nil
+	if err = outformats.Close(writer); err != nil {
+		return err
+	}
+	return m.write(outformats.Files(writer))
 }
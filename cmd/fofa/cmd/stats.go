@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
-	"os"
 	"strings"
+	"time"
 )
 
 // stats subcommand
@@ -36,6 +36,7 @@ var statsCmd = &cli.Command{
 			Usage:       "use custom fields",
 			Destination: &customFields,
 		},
+		manifestFlag(),
 	},
 	Action: statsAction,
 }
@@ -59,20 +60,34 @@ func statsAction(ctx *cli.Context) error {
 		return errors.New("fofa fields cannot be empty")
 	}
 
+	m := newManifest("stats", fields, nil)
+
 	// do search
+	start := time.Now()
 	res, err := fofaCli.Stats(query, size, fields)
 	if err != nil {
 		return err
 	}
 
+	out := stdoutWriter()
+	rows := 0
 	for _, obj := range res {
-		color.New(color.FgBlue).Fprintln(os.Stdout, "=== ", obj.Name)
+		color.New(color.FgBlue).Fprintln(out, "=== ", obj.Name)
 		for _, item := range obj.Items {
-			color.New(color.FgHiGreen).Fprint(os.Stdout, item.Name)
-			fmt.Print("\t")
-			color.New(color.FgHiYellow).Fprintln(os.Stdout, item.Count)
+			color.New(color.FgHiGreen).Fprint(out, item.Name)
+			fmt.Fprint(out, "\t")
+			color.New(color.FgHiYellow).Fprintln(out, item.Count)
+			rows++
+		}
+	}
+	if m != nil {
+		// 统计接口不返回总数，使用size查询获取
+		total, err := fofaCli.HostSize(query)
+		if err != nil {
+			return err
 		}
+		m.addQuery(query, total, rows, start)
 	}
 
-	return nil
+	return m.write(nil)
 }
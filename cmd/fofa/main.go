@@ -77,6 +77,7 @@ Examples:
 		os.Args = newArgs
 	}
 
+	cmd.Version, cmd.Commit, cmd.BuildDate = version, commit, date
 	err := app.Run(os.Args)
 	if err != nil {
 		log.Fatal(err)
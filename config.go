@@ -1,8 +1,18 @@
 package gofofa
 
 import (
+	"errors"
 	"fmt"
 	"github.com/FofaInfo/GoFOFA/pkg/readformats"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// ConfigFileName config file of current dir
+	ConfigFileName = ".fofa.yaml"
+	// LegacyConfigFileName config file of current dir of early versions, lower than ConfigFileName
+	LegacyConfigFileName = "config.yaml"
 )
 
 type Cate struct {
@@ -15,9 +25,29 @@ type CusFields struct {
 	Fields string `yaml:"fields"`
 }
 
+// Profile connection settings of a named account, empty ones are not changed
+type Profile struct {
+	Server     string `yaml:"server"`      // format: <scheme>://<host>
+	Email      string `yaml:"email"`       // fofa email
+	Key        string `yaml:"key"`         // fofa key
+	DeductMode string `yaml:"deduct_mode"` // DeductModeFree or DeductModeFCoin
+	Proxy      string `yaml:"proxy"`       // proxy url, like http://127.0.0.1:8080 or socks5://127.0.0.1:1080
+	Credential string `yaml:"credential"`  // name of credential of DefaultCredentialStore used if key is empty
+}
+
 type Config struct {
 	Categories   []Cate      `yaml:"categories"`
 	CustomFields []CusFields `yaml:"custom_fields"`
+
+	Profile  string                       `yaml:"profile"`  // default profile
+	Profiles map[string]Profile           `yaml:"profiles"` // profiles by name
+	Defaults map[string]map[string]string `yaml:"defaults"` // default flags by command, like dump: {fields: ip,port,host}
+
+	Files []string `yaml:"-"` // files merged into config, from the lowest precedence
+
+	// LegacyError error of loading ./config.yaml, which is skipped as it may be a config of other tools,
+	// commands need categories of it should fail with this error
+	LegacyError error `yaml:"-"`
 }
 
 func LoadConfig(configFile string) (*Config, error) {
@@ -29,3 +59,118 @@ func LoadConfig(configFile string) (*Config, error) {
 	}
 	return &config, nil
 }
+
+// UserConfigDir dir of config of current user, $XDG_CONFIG_HOME/fofa or ~/.config/fofa
+func UserConfigDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); len(dir) > 0 {
+		return filepath.Join(dir, "fofa"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "fofa"), nil
+}
+
+// ConfigFiles existing config files from the lowest precedence:
+// $XDG_CONFIG_HOME/fofa/config.yaml, ./config.yaml, ./.fofa.yaml and configFile, which should exist if not empty
+func ConfigFiles(configFile string) ([]string, error) {
+	var candidates []string
+	if dir, err := UserConfigDir(); err == nil {
+		candidates = append(candidates, filepath.Join(dir, "config.yaml"))
+	}
+	candidates = append(candidates, LegacyConfigFileName, ConfigFileName)
+
+	var files []string
+	for _, file := range candidates {
+		if info, err := os.Stat(file); err == nil && !info.IsDir() {
+			files = append(files, file)
+		}
+	}
+	if len(configFile) > 0 {
+		if _, err := os.Stat(configFile); err != nil {
+			return nil, fmt.Errorf("config file not found: %v", err)
+		}
+		files = append(files, configFile)
+	}
+	return files, nil
+}
+
+// LoadConfigs load and merge config files of ConfigFiles, empty config if not any.
+// ./config.yaml failed to load is skipped with LegacyError, unless it is configFile.
+func LoadConfigs(configFile string) (*Config, error) {
+	files, err := ConfigFiles(configFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	for _, file := range files {
+		c, err := LoadConfig(file)
+		if err != nil {
+			if file == LegacyConfigFileName && file != configFile {
+				config.LegacyError = fmt.Errorf("%s: %v", file, err)
+				continue
+			}
+			return nil, err
+		}
+		config.merge(c)
+		config.Files = append(config.Files, file)
+	}
+	return config, nil
+}
+
+// merge config of higher precedence: categories and custom fields are replaced if not empty,
+// profiles are replaced by name, and defaults by command and flag
+func (c *Config) merge(other *Config) {
+	if len(other.Categories) > 0 {
+		c.Categories = other.Categories
+	}
+	if len(other.CustomFields) > 0 {
+		c.CustomFields = other.CustomFields
+	}
+	if len(other.Profile) > 0 {
+		c.Profile = other.Profile
+	}
+	for name, profile := range other.Profiles {
+		if c.Profiles == nil {
+			c.Profiles = make(map[string]Profile)
+		}
+		c.Profiles[name] = profile
+	}
+	for command, flags := range other.Defaults {
+		if c.Defaults == nil {
+			c.Defaults = make(map[string]map[string]string)
+		}
+		if c.Defaults[command] == nil {
+			c.Defaults[command] = make(map[string]string)
+		}
+		for name, value := range flags {
+			c.Defaults[command][name] = value
+		}
+	}
+}
+
+// GetProfile profile of name, the default profile if name is empty,
+// ok is false if name is empty and there is no default profile
+func (c *Config) GetProfile(name string) (profile Profile, ok bool, err error) {
+	if len(name) == 0 {
+		if name = c.Profile; len(name) == 0 {
+			return Profile{}, false, nil
+		}
+	}
+	profile, ok = c.Profiles[name]
+	if !ok {
+		return Profile{}, false, fmt.Errorf("profile not found: %s", name)
+	}
+	return profile, true, nil
+}
+
+// GetCustomFields fields of custom fields of name
+func (c *Config) GetCustomFields(name string) (string, error) {
+	for _, field := range c.CustomFields {
+		if field.Name == name {
+			return field.Fields, nil
+		}
+	}
+	return "", errors.New("field not found")
+}
@@ -3,6 +3,7 @@ package gofofa
 import (
 	"github.com/stretchr/testify/assert"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -32,3 +33,66 @@ func TestLoadConfig(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "read Config file failed")
 }
+
+func TestLoadConfigs(t *testing.T) {
+	tempDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(originalDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tempDir, "xdg"))
+
+	// 没有配置文件
+	config, err := LoadConfigs("")
+	assert.Nil(t, err)
+	assert.Empty(t, config.Files)
+	_, ok, err := config.GetProfile("")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(tempDir, "xdg", "fofa"), 0755))
+	userFile := filepath.Join(tempDir, "xdg", "fofa", "config.yaml")
+	assert.Nil(t, os.WriteFile(userFile, []byte("profile: work\nprofiles:\n  work:\n    email: a@a.com\n    key: \"1\"\n"+
+		"  home:\n    email: b@b.com\ndefaults:\n  dump:\n    fields: ip,port\n    size: 100\n"+
+		"custom_fields:\n  - name: web\n    fields: host,title\n"), 0644))
+	assert.Nil(t, os.WriteFile(ConfigFileName, []byte("profiles:\n  home:\n    email: c@c.com\ndefaults:\n  dump:\n    size: 10\n"+
+		"categories:\n  - name: web\n    filters:\n      - \"port == '80'\"\n"), 0644))
+	assert.Nil(t, os.WriteFile("other.yaml", []byte("profile: home\n"), 0644))
+
+	config, err = LoadConfigs("")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{userFile, ConfigFileName}, config.Files)
+	profile, ok, err := config.GetProfile("")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "a@a.com", profile.Email)
+	profile, _, err = config.GetProfile("home")
+	assert.Nil(t, err)
+	assert.Equal(t, "c@c.com", profile.Email)
+	_, _, err = config.GetProfile("nope")
+	assert.Error(t, err)
+	assert.Equal(t, map[string]string{"fields": "ip,port", "size": "10"}, config.Defaults["dump"])
+	assert.Len(t, config.Categories, 1)
+	fields, err := config.GetCustomFields("web")
+	assert.Nil(t, err)
+	assert.Equal(t, "host,title", fields)
+
+	// 指定的配置文件优先级最高
+	config, err = LoadConfigs("other.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, "home", config.Profile)
+	_, err = LoadConfigs("missing.yaml")
+	assert.Error(t, err)
+
+	// 无法解析的./config.yaml可能属于其他工具，跳过
+	assert.Nil(t, os.WriteFile(LegacyConfigFileName, []byte("profiles: [a\n"), 0644))
+	config, err = LoadConfigs("")
+	assert.Nil(t, err)
+	assert.ErrorContains(t, config.LegacyError, LegacyConfigFileName)
+	assert.Equal(t, []string{userFile, ConfigFileName}, config.Files)
+	assert.Len(t, config.Categories, 1)
+	_, err = LoadConfigs(LegacyConfigFileName)
+	assert.Error(t, err)
+	assert.Nil(t, os.WriteFile(ConfigFileName, []byte("profiles: [a\n"), 0644))
+	_, err = LoadConfigs("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,314 @@
+package gofofa
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// CredentialFileName file of encrypted credentials in UserConfigDir
+	CredentialFileName = "credentials"
+	// CredentialKeyFileName key file of credentials not encrypted by passphrase in UserConfigDir
+	CredentialKeyFileName = "credentials.key"
+	// DefaultCredential name of credential used if not referenced by profile
+	DefaultCredential = "default"
+
+	// CredentialModePassphrase credentials encrypted by key derived from passphrase
+	CredentialModePassphrase = "passphrase"
+	// CredentialModeKeyFile credentials encrypted by key of key file
+	CredentialModeKeyFile = "keyfile"
+
+	credentialIterations    = 600000 // iterations of pbkdf2
+	credentialMinIterations = 600000 // files of fewer iterations are rejected
+	credentialSaltSize      = 16
+	credentialKeySize       = 32 // aes-256
+)
+
+var (
+	// ErrPassphraseRequired credentials are encrypted by passphrase, which is not set
+	ErrPassphraseRequired = errors.New("credentials are encrypted by passphrase, set it by env FOFA_PASSPHRASE")
+	// ErrCredentialDecrypt wrong passphrase or key file
+	ErrCredentialDecrypt = errors.New("failed to decrypt credentials, wrong passphrase or key file")
+)
+
+// Credential fofa account stored in CredentialStore
+type Credential struct {
+	Server string `json:"server,omitempty"` // format: <scheme>://<host>, default server if empty
+	Email  string `json:"email,omitempty"`
+	Key    string `json:"key"`
+}
+
+// MaskedKey key with only the first and the last 4 characters, like abcd********wxyz
+func (c Credential) MaskedKey() string {
+	if len(c.Key) <= 8 {
+		return strings.Repeat("*", len(c.Key))
+	}
+	return c.Key[:4] + strings.Repeat("*", len(c.Key)-8) + c.Key[len(c.Key)-4:]
+}
+
+// credentialFile content of credential file, data is json of credentials by name encrypted by aes-gcm
+type credentialFile struct {
+	Version    int    `json:"version"`
+	Mode       string `json:"mode"`                 // passphrase or keyfile
+	Salt       []byte `json:"salt,omitempty"`       // salt of pbkdf2 in passphrase mode
+	Iterations int    `json:"iterations,omitempty"` // iterations of pbkdf2 in passphrase mode
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// CredentialStore credentials by name in a local file encrypted by AES-256-GCM,
+// whose key is derived from passphrase by PBKDF2-SHA256, or read from a random key file
+// created on the first save, so that no key is kept in plaintext env, flags or config
+type CredentialStore struct {
+	File       string // file of encrypted credentials
+	KeyFile    string // key file used if Passphrase is empty
+	Passphrase string // passphrase of saving, and of loading credentials encrypted by passphrase
+
+	derived *derivedKey // last key derived from passphrase, reused by Save
+}
+
+// derivedKey key derived from passphrase and salt
+type derivedKey struct {
+	passphrase string
+	salt       []byte
+	iterations int
+	key        []byte
+}
+
+// DefaultCredentialStore store of files in UserConfigDir, passphrase is read from env FOFA_PASSPHRASE
+func DefaultCredentialStore() (*CredentialStore, error) {
+	dir, err := UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &CredentialStore{
+		File:       filepath.Join(dir, CredentialFileName),
+		KeyFile:    filepath.Join(dir, CredentialKeyFileName),
+		Passphrase: os.Getenv("FOFA_PASSPHRASE"),
+	}, nil
+}
+
+// Exists whether the credential file exists
+func (s *CredentialStore) Exists() bool {
+	_, err := os.Stat(s.File)
+	return err == nil
+}
+
+// Mode CredentialModePassphrase or CredentialModeKeyFile of the credential file, empty if not exists
+func (s *CredentialStore) Mode() (string, error) {
+	f, err := s.readFile()
+	if err != nil || f == nil {
+		return "", err
+	}
+	return f.Mode, nil
+}
+
+// deriveKey key of passphrase by PBKDF2-SHA256, the last one is cached
+// so that Load and Save of Set or Delete derive only once
+func (s *CredentialStore) deriveKey(salt []byte, iterations int) []byte {
+	if d := s.derived; d != nil && d.passphrase == s.Passphrase &&
+		d.iterations == iterations && string(d.salt) == string(salt) {
+		return d.key
+	}
+	key := pbkdf2.Key([]byte(s.Passphrase), salt, iterations, credentialKeySize, sha256.New)
+	s.derived = &derivedKey{passphrase: s.Passphrase, salt: salt, iterations: iterations, key: key}
+	return key
+}
+
+func (s *CredentialStore) readFile() (*credentialFile, error) {
+	data, err := os.ReadFile(s.File)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var f credentialFile
+	if err = json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid credential file %s: %v", s.File, err)
+	}
+	return &f, nil
+}
+
+// readKeyFile hex key of key file, created if not exists and create is true
+func (s *CredentialStore) readKeyFile(create bool) ([]byte, error) {
+	data, err := os.ReadFile(s.KeyFile)
+	if os.IsNotExist(err) && create {
+		key := make([]byte, credentialKeySize)
+		if _, err = rand.Read(key); err != nil {
+			return nil, err
+		}
+		if err = os.MkdirAll(filepath.Dir(s.KeyFile), 0700); err != nil {
+			return nil, err
+		}
+		if err = os.WriteFile(s.KeyFile, []byte(hex.EncodeToString(key)+"\n"), 0600); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read key file failed: %v", err)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != credentialKeySize {
+		return nil, fmt.Errorf("invalid key file: %s", s.KeyFile)
+	}
+	return key, nil
+}
+
+// Load all credentials by name, empty if the credential file not exists
+func (s *CredentialStore) Load() (map[string]Credential, error) {
+	f, err := s.readFile()
+	if err != nil {
+		return nil, err
+	}
+	credentials := make(map[string]Credential)
+	if f == nil {
+		return credentials, nil
+	}
+
+	var key []byte
+	switch f.Mode {
+	case CredentialModePassphrase:
+		if len(s.Passphrase) == 0 {
+			return nil, ErrPassphraseRequired
+		}
+		// 拒绝被篡改为低迭代次数的文件
+		if f.Iterations < credentialMinIterations || len(f.Salt) < credentialSaltSize {
+			return nil, fmt.Errorf("invalid credential file %s: weak key derivation", s.File)
+		}
+		key = s.deriveKey(f.Salt, f.Iterations)
+	case CredentialModeKeyFile:
+		if key, err = s.readKeyFile(false); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown mode of credential file: %s", f.Mode)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	data, err := gcm.Open(nil, f.Nonce, f.Data, []byte(f.Mode))
+	if err != nil {
+		return nil, ErrCredentialDecrypt
+	}
+	if err = json.Unmarshal(data, &credentials); err != nil {
+		return nil, fmt.Errorf("invalid credentials: %v", err)
+	}
+	return credentials, nil
+}
+
+// Save all credentials, encrypted by passphrase if set, or by key file,
+// the credential file and the key file are removed if credentials is empty
+func (s *CredentialStore) Save(credentials map[string]Credential) error {
+	if len(credentials) == 0 {
+		for _, file := range []string{s.File, s.KeyFile} {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	f := credentialFile{Version: 1, Mode: CredentialModeKeyFile}
+	var key []byte
+	var err error
+	if len(s.Passphrase) > 0 {
+		f.Mode = CredentialModePassphrase
+		f.Iterations = credentialIterations
+		// 口令未变时沿用已派生的密钥，每次保存使用新的nonce
+		if d := s.derived; d != nil && d.passphrase == s.Passphrase && d.iterations == f.Iterations {
+			f.Salt = d.salt
+		} else {
+			f.Salt = make([]byte, credentialSaltSize)
+			if _, err = rand.Read(f.Salt); err != nil {
+				return err
+			}
+		}
+		key = s.deriveKey(f.Salt, f.Iterations)
+	} else if key, err = s.readKeyFile(true); err != nil {
+		return err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	f.Data = gcm.Seal(nil, f.Nonce, data, []byte(f.Mode))
+
+	data, err = json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	// 先写临时文件再替换，避免写入中断损坏已有凭据
+	if err = os.MkdirAll(filepath.Dir(s.File), 0700); err != nil {
+		return err
+	}
+	tmp := s.File + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.File)
+}
+
+// Get credential of name, ok is false if not exists
+func (s *CredentialStore) Get(name string) (credential Credential, ok bool, err error) {
+	credentials, err := s.Load()
+	if err != nil {
+		return Credential{}, false, err
+	}
+	credential, ok = credentials[name]
+	return credential, ok, nil
+}
+
+// Set credential of name
+func (s *CredentialStore) Set(name string, credential Credential) error {
+	credentials, err := s.Load()
+	if err != nil {
+		return err
+	}
+	credentials[name] = credential
+	return s.Save(credentials)
+}
+
+// Delete credential of name, returns whether it existed
+func (s *CredentialStore) Delete(name string) (bool, error) {
+	credentials, err := s.Load()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := credentials[name]; !ok {
+		return false, nil
+	}
+	delete(credentials, name)
+	return true, s.Save(credentials)
+}
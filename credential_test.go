@@ -0,0 +1,119 @@
+package gofofa
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCredentialStore_DeriveKey(t *testing.T) {
+	dir := t.TempDir()
+	store := &CredentialStore{File: filepath.Join(dir, CredentialFileName), Passphrase: "passwd"}
+
+	// RFC 7914 test vector of PBKDF2-HMAC-SHA256
+	assert.Equal(t, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc",
+		hex.EncodeToString(store.deriveKey([]byte("salt"), 1)))
+
+	// Set的Load和Save只派生一次密钥，保存时沿用盐
+	assert.Nil(t, store.Set(DefaultCredential, Credential{Key: "key1"}))
+	f, err := store.readFile()
+	assert.Nil(t, err)
+	assert.Equal(t, credentialIterations, f.Iterations)
+	derived := store.derived
+	assert.Nil(t, store.Set("lab", Credential{Key: "key2"}))
+	assert.Same(t, derived, store.derived)
+	f2, err := store.readFile()
+	assert.Nil(t, err)
+	assert.Equal(t, f.Salt, f2.Salt)
+	assert.NotEqual(t, f.Nonce, f2.Nonce)
+
+	// 更换口令后使用新的盐
+	store.Passphrase = "other"
+	assert.Nil(t, store.Save(map[string]Credential{DefaultCredential: {Key: "key1"}}))
+	f3, err := store.readFile()
+	assert.Nil(t, err)
+	assert.NotEqual(t, f.Salt, f3.Salt)
+
+	// 拒绝低迭代次数
+	f3.Iterations = 1000
+	data, err := json.Marshal(f3)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(store.File, data, 0600))
+	_, err = store.Load()
+	assert.ErrorContains(t, err, "weak key derivation")
+}
+
+func TestCredential_MaskedKey(t *testing.T) {
+	assert.Equal(t, "abcd********7890", Credential{Key: "abcdef1234567890"}.MaskedKey())
+	assert.Equal(t, "*****", Credential{Key: "12345"}.MaskedKey())
+}
+
+func TestCredentialStore(t *testing.T) {
+	dir := t.TempDir()
+	store := &CredentialStore{File: filepath.Join(dir, "fofa", CredentialFileName), KeyFile: filepath.Join(dir, "fofa", CredentialKeyFileName)}
+
+	// 不存在
+	assert.False(t, store.Exists())
+	_, ok, err := store.Get(DefaultCredential)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	// 密钥文件模式
+	credential := Credential{Server: "https://fofa.info", Email: "a@a.com", Key: "1234567890abcdef"}
+	assert.Nil(t, store.Set(DefaultCredential, credential))
+	mode, err := store.Mode()
+	assert.Nil(t, err)
+	assert.Equal(t, CredentialModeKeyFile, mode)
+	data, err := os.ReadFile(store.File)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), credential.Key)
+	info, err := os.Stat(store.KeyFile)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	c, ok, err := store.Get(DefaultCredential)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, credential, c)
+
+	// 错误的密钥文件
+	key, _ := os.ReadFile(store.KeyFile)
+	assert.Nil(t, os.WriteFile(store.KeyFile, []byte(strings.Repeat("0", len(strings.TrimSpace(string(key))))), 0600))
+	_, err = store.Load()
+	assert.ErrorIs(t, err, ErrCredentialDecrypt)
+	assert.Nil(t, os.WriteFile(store.KeyFile, key, 0600))
+
+	// 设置口令后改为口令模式
+	store.Passphrase = "secret"
+	assert.Nil(t, store.Set("lab", Credential{Key: "key2"}))
+	mode, _ = store.Mode()
+	assert.Equal(t, CredentialModePassphrase, mode)
+	credentials, err := store.Load()
+	assert.Nil(t, err)
+	assert.Len(t, credentials, 2)
+
+	other := &CredentialStore{File: store.File, KeyFile: store.KeyFile}
+	_, err = other.Load()
+	assert.ErrorIs(t, err, ErrPassphraseRequired)
+	other.Passphrase = "wrong"
+	_, err = other.Load()
+	assert.ErrorIs(t, err, ErrCredentialDecrypt)
+
+	// 全部删除后移除文件
+	ok, err = store.Delete("lab")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, err = store.Delete("lab")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	ok, err = store.Delete(DefaultCredential)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.False(t, store.Exists())
+	_, err = os.Stat(store.KeyFile)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,323 @@
+package gofofa
+
+import (
+	"fmt"
+	"github.com/FofaInfo/GoFOFA/pkg/dedup"
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/parser"
+	"github.com/expr-lang/expr/vm"
+	"github.com/weppos/publicsuffix-go/publicsuffix"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// exprFunctions functions of filter and category expressions, values of fields are strings
+var exprFunctions = map[string]interface{}{
+	"CONTAIN":       strings.Contains,
+	"inCIDR":        inCIDR,
+	"regexMatch":    regexMatch,
+	"regexExtract":  regexExtract,
+	"semverCompare": semverCompare,
+	"int":           toInt,
+	"float":         toFloat,
+	"domainOf":      domainOf,
+	"tld":           tld,
+	"before":        before,
+	"after":         after,
+	"hasItem":       hasItem,
+}
+
+// exprEnv env of expressions of fields with functions, values of fields are set for each row
+func exprEnv(fields []string) map[string]interface{} {
+	env := make(map[string]interface{}, len(fields)+len(exprFunctions))
+	for name, fn := range exprFunctions {
+		env[name] = fn
+	}
+	for _, field := range fields {
+		env[field] = ""
+	}
+	return env
+}
+
+// compileExpr compile expression returns bool against env of exprEnv,
+// builtin int and float are replaced by those accept empty strings
+func compileExpr(filter string, env map[string]interface{}) (*vm.Program, error) {
+	return expr.Compile(filter, expr.Env(env), expr.AsBool(),
+		expr.DisableBuiltin("int"), expr.DisableBuiltin("float"))
+}
+
+// variableVisitor collect identifiers which are not functions
+type variableVisitor struct {
+	variables []string
+}
+
+func (v *variableVisitor) Visit(node *ast.Node) {
+	if n, ok := (*node).(*ast.IdentifierNode); ok {
+		if _, isFunc := exprFunctions[n.Value]; !isFunc && !slices.Contains(v.variables, n.Value) {
+			v.variables = append(v.variables, n.Value)
+		}
+	}
+}
+
+// exprVariables fields referred by expression
+func exprVariables(filter string) ([]string, error) {
+	tree, err := parser.Parse(filter)
+	if err != nil {
+		return nil, err
+	}
+	v := &variableVisitor{}
+	ast.Walk(&tree.Node, v)
+	return v.variables, nil
+}
+
+var (
+	prefixCache sync.Map // cidr to netip.Prefix
+	regexpCache sync.Map // pattern to *regexp.Regexp
+)
+
+// inCIDR whether ip is in any of cidrs like 10.0.0.0/8, each can be comma separated, false if ip is invalid
+func inCIDR(ip string, cidrs ...string) (bool, error) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return false, nil
+	}
+	addr = addr.Unmap()
+	for _, list := range cidrs {
+		for _, cidr := range strings.Split(list, ",") {
+			cidr = strings.TrimSpace(cidr)
+			var prefix netip.Prefix
+			if v, ok := prefixCache.Load(cidr); ok {
+				prefix = v.(netip.Prefix)
+			} else {
+				if !strings.Contains(cidr, "/") {
+					var single netip.Addr
+					if single, err = netip.ParseAddr(cidr); err == nil {
+						prefix = netip.PrefixFrom(single, single.BitLen())
+					}
+				} else {
+					prefix, err = netip.ParsePrefix(cidr)
+				}
+				if err != nil {
+					return false, fmt.Errorf("invalid cidr: %s", cidr)
+				}
+				prefix = prefix.Masked()
+				prefixCache.Store(cidr, prefix)
+			}
+			if prefix.Contains(addr) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// compileRegexp compiled pattern, cached
+func compileRegexp(pattern string) (*regexp.Regexp, error) {
+	if v, ok := regexpCache.Load(pattern); ok {
+		return v.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexpCache.Store(pattern, re)
+	return re, nil
+}
+
+// regexMatch whether s matches pattern
+func regexMatch(s, pattern string) (bool, error) {
+	re, err := compileRegexp(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(s), nil
+}
+
+// regexExtract the first group of the first match of pattern in s, or the match if pattern has no group,
+// empty if not matched
+func regexExtract(s, pattern string) (string, error) {
+	re, err := compileRegexp(pattern)
+	if err != nil {
+		return "", err
+	}
+	match := re.FindStringSubmatch(s)
+	switch {
+	case match == nil:
+		return "", nil
+	case len(match) > 1:
+		return match[1], nil
+	}
+	return match[0], nil
+}
+
+// semverCompare compare versions like v1.2.3, 1.10 and 2.0.0-beta, returns -1, 0 or 1,
+// missing parts are 0, and a pre-release is less than its release
+func semverCompare(a, b string) int {
+	split := func(v string) ([]string, string) {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+		v, _, _ = strings.Cut(v, "+")
+		v, pre, _ := strings.Cut(v, "-")
+		return strings.Split(v, "."), pre
+	}
+	partsA, preA := split(a)
+	partsB, preB := split(b)
+	for i := 0; i < max(len(partsA), len(partsB)); i++ {
+		pa, pb := "0", "0"
+		if i < len(partsA) {
+			pa = partsA[i]
+		}
+		if i < len(partsB) {
+			pb = partsB[i]
+		}
+		na, errA := strconv.Atoi(pa)
+		nb, errB := strconv.Atoi(pb)
+		if errA != nil || errB != nil {
+			// not numbers, compare as text
+			if c := strings.Compare(pa, pb); c != 0 {
+				return c
+			}
+			continue
+		}
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case len(preA) == 0:
+		return 1
+	case len(preB) == 0:
+		return -1
+	}
+	return strings.Compare(preA, preB)
+}
+
+// toInt int of number or string, 0 if empty
+func toInt(v interface{}) (int, error) {
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case float64:
+		return int(n), nil
+	case string:
+		n = strings.TrimSpace(n)
+		if len(n) == 0 {
+			return 0, nil
+		}
+		if i, err := strconv.Atoi(n); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid int: %s", n)
+		}
+		return int(f), nil
+	}
+	return 0, fmt.Errorf("invalid int: %v", v)
+}
+
+// toFloat float of number or string, 0 if empty
+func toFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	case string:
+		n = strings.TrimSpace(n)
+		if len(n) == 0 {
+			return 0, nil
+		}
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid float: %s", n)
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("invalid float: %v", v)
+}
+
+// domainOf registrable domain of host or url, like example.co.uk of a.example.co.uk:443, empty if not a domain
+func domainOf(host string) string {
+	hostname := dedup.Hostname(host)
+	if _, err := netip.ParseAddr(hostname); err == nil {
+		return ""
+	}
+	domain, err := publicsuffix.Domain(hostname)
+	if err != nil {
+		return ""
+	}
+	return domain
+}
+
+// tld public suffix of host or url, like co.uk of a.example.co.uk, empty if not a domain
+func tld(host string) string {
+	hostname := dedup.Hostname(host)
+	if _, err := netip.ParseAddr(hostname); err == nil {
+		return ""
+	}
+	dn, err := publicsuffix.Parse(hostname)
+	if err != nil {
+		return ""
+	}
+	return dn.TLD
+}
+
+// timeLayouts layouts of time of fields like lastupdatetime
+var timeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02", time.RFC3339, "2006-01-02T15:04:05"}
+
+// parseTime parse time of timeLayouts
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %s", s)
+}
+
+// compareTime compare time of field with date, ok is false if field is not a time
+func compareTime(field, date string) (c int, ok bool, err error) {
+	d, err := parseTime(date)
+	if err != nil {
+		return 0, false, err
+	}
+	t, err := parseTime(field)
+	if err != nil {
+		return 0, false, nil
+	}
+	return t.Compare(d), true, nil
+}
+
+// before whether time of field like lastupdatetime is before date like 2024-01-01, false if field is not a time
+func before(field, date string) (bool, error) {
+	c, ok, err := compareTime(field, date)
+	return ok && c < 0, err
+}
+
+// after whether time of field like lastupdatetime is after date like 2024-01-01, false if field is not a time
+func after(field, date string) (bool, error) {
+	c, ok, err := compareTime(field, date)
+	return ok && c > 0, err
+}
+
+// hasItem whether comma separated list like product has any of items
+func hasItem(list string, items ...string) bool {
+	for _, v := range strings.Split(list, ",") {
+		if slices.Contains(items, strings.TrimSpace(v)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package gofofa
+
+import (
+	"github.com/expr-lang/expr"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExprVariables(t *testing.T) {
+	variables, err := exprVariables(`int(port) >= 8000 && !inCIDR(ip, "10.0.0.0/8") || CONTAIN(title, "x") && title != ""`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"port", "ip", "title"}, variables)
+
+	_, err = exprVariables(`port >`)
+	assert.Error(t, err)
+}
+
+func TestCompileExpr(t *testing.T) {
+	env := exprEnv([]string{"ip", "port", "host", "product", "version", "lastupdatetime", "title"})
+	run := func(filter string, values map[string]string) (bool, error) {
+		program, err := compileExpr(filter, env)
+		if err != nil {
+			return false, err
+		}
+		for k, v := range values {
+			env[k] = v
+		}
+		output, err := expr.Run(program, env)
+		if err != nil {
+			return false, err
+		}
+		return output.(bool), nil
+	}
+
+	match, err := run(`int(port) >= 8000 && int(port) <= 9000 && !inCIDR(ip, "10.0.0.0/8", "192.168.0.0/16,172.16.0.0/12")`,
+		map[string]string{"ip": "8.8.8.8", "port": "8080"})
+	assert.Nil(t, err)
+	assert.True(t, match)
+	match, err = run(`int(port) >= 8000 && !inCIDR(ip, "10.0.0.0/8", "192.168.0.0/16,172.16.0.0/12")`,
+		map[string]string{"ip": "172.20.1.1", "port": "8080"})
+	assert.Nil(t, err)
+	assert.False(t, match)
+	match, err = run(`int(port) == 0 && float(port) < 1.5`, map[string]string{"port": ""})
+	assert.Nil(t, err)
+	assert.True(t, match)
+	_, err = run(`int(port) > 0`, map[string]string{"port": "http"})
+	assert.ErrorContains(t, err, "invalid int")
+
+	match, err = run(`domainOf(host) == "example.co.uk" && tld(host) == "co.uk" && hasItem(product, "nginx", "iis")`,
+		map[string]string{"host": "https://a.example.co.uk:8443", "product": "php, nginx"})
+	assert.Nil(t, err)
+	assert.True(t, match)
+	match, err = run(`before(lastupdatetime, "2024-01-01") && semverCompare(version, "1.18.0") < 0 && regexExtract(title, "v(\\d+)") == "2"`,
+		map[string]string{"lastupdatetime": "2023-12-31 23:59:59", "version": "v1.9.2", "title": "app v2"})
+	assert.Nil(t, err)
+	assert.True(t, match)
+
+	// 编译期检查
+	_, err = run(`unknown == ""`, nil)
+	assert.Error(t, err)
+	_, err = run(`port`, nil)
+	assert.Error(t, err)
+	_, err = run(`inCIDR(ip, "10.0.0.0/33")`, map[string]string{"ip": "10.0.0.1"})
+	assert.ErrorContains(t, err, "invalid cidr")
+	_, err = run(`regexMatch(title, "(")`, nil)
+	assert.Error(t, err)
+}
+
+func TestExprFunctions(t *testing.T) {
+	ok, err := inCIDR("10.1.2.3", "10.0.0.0/8")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, _ = inCIDR("::ffff:10.1.2.3", "10.1.2.3")
+	assert.True(t, ok)
+	ok, _ = inCIDR("2001:db8::1", "2001:db8::/32")
+	assert.True(t, ok)
+	ok, err = inCIDR("", "10.0.0.0/8")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	ok, _ = regexMatch("nginx/1.18.0", `^nginx/\d+`)
+	assert.True(t, ok)
+	v, _ := regexExtract("nginx/1.18.0", `nginx/([\d.]+)`)
+	assert.Equal(t, "1.18.0", v)
+	v, _ = regexExtract("nginx/1.18.0", `\d+`)
+	assert.Equal(t, "1", v)
+	v, _ = regexExtract("apache", `\d+`)
+	assert.Equal(t, "", v)
+
+	assert.Equal(t, 0, semverCompare("v1.2", "1.2.0"))
+	assert.Equal(t, 1, semverCompare("1.10.0", "1.9.9"))
+	assert.Equal(t, -1, semverCompare("2.0.0-beta", "2.0.0"))
+	assert.Equal(t, -1, semverCompare("2.0.0-alpha", "2.0.0-beta"))
+	assert.Equal(t, 0, semverCompare("1.0.0+build1", "1.0.0"))
+
+	assert.Equal(t, "example.com", domainOf("a.b.example.com"))
+	assert.Equal(t, "", domainOf("1.1.1.1:80"))
+	assert.Equal(t, "com", tld("example.com"))
+
+	ok, err = after("2024-01-02", "2024-01-01 12:00:00")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, err = before("", "2024-01-01")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	_, err = before("2024-01-01", "yesterday")
+	assert.Error(t, err)
+
+	assert.True(t, hasItem("a,b, c", "c"))
+	assert.False(t, hasItem("a,bc", "b"))
+}
@@ -2,6 +2,7 @@ package gofofa
 
 import (
 	"context"
+	"errors"
 	"os"
 )
 
@@ -32,7 +33,26 @@ func newClientFromEnv() (*Client, error) {
 	return c, nil
 }
 
-// FofaURLFromEnv parse fofa connection url from env, then generate url string
+// storedCredential credential of FOFA_CREDENTIAL, or DefaultCredential in DefaultCredentialStore,
+// ok is false if the credential file not exists, or its passphrase is not set
+func storedCredential() (credential Credential, ok bool, err error) {
+	store, err := DefaultCredentialStore()
+	if err != nil || !store.Exists() {
+		return Credential{}, false, nil
+	}
+	name := os.Getenv("FOFA_CREDENTIAL")
+	if len(name) == 0 {
+		name = DefaultCredential
+	}
+	credential, ok, err = store.Get(name)
+	if errors.Is(err, ErrPassphraseRequired) {
+		return Credential{}, false, nil
+	}
+	return credential, ok, err
+}
+
+// FofaURLFromEnv parse fofa connection url from env, then generate url string,
+// stored credentials are not included
 func FofaURLFromEnv() string {
 	c, err := newClientFromEnv()
 	if err != nil {
@@ -41,3 +61,14 @@ func FofaURLFromEnv() string {
 
 	return c.URL()
 }
+
+// FofaServerFromEnv server of fofa connection from env, without email and key,
+// so that it can be shown as default value of flags
+func FofaServerFromEnv() string {
+	c, err := newClientFromEnv()
+	if err != nil {
+		return defaultServer
+	}
+
+	return c.Server
+}
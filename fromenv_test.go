@@ -32,3 +32,54 @@ func TestFofaURLFromEnv(t *testing.T) {
 	os.Setenv("FOFA_CLIENT_URL", "https://2.2.2.2/?email=b@b.com&key=000000&version=v2")
 	assert.Equal(t, "https://2.2.2.2/?email=b@b.com&key=000000&version=v2", FofaURLFromEnv())
 }
+
+func TestFofaURLFromEnv_Credential(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("FOFA_CLIENT_URL", "")
+	t.Setenv("FOFA_SERVER", "")
+	t.Setenv("FOFA_EMAIL", "")
+	t.Setenv("FOFA_KEY", "")
+	t.Setenv("FOFA_PASSPHRASE", "")
+
+	store, err := DefaultCredentialStore()
+	assert.Nil(t, err)
+	assert.Nil(t, store.Set(DefaultCredential, Credential{Server: "https://1.1.1.1", Email: "a@a.com", Key: "123456"}))
+	assert.Nil(t, store.Set("lab", Credential{Key: "654321"}))
+
+	// 存储的凭据不会出现在参数默认值中
+	assert.Equal(t, "https://fofa.info/?email=&key=&version=v1", FofaURLFromEnv())
+	assert.Equal(t, "https://fofa.info", FofaServerFromEnv())
+	t.Setenv("FOFA_SERVER", "https://2.2.2.2")
+	assert.Equal(t, "https://2.2.2.2", FofaServerFromEnv())
+
+	credential, ok, err := storedCredential()
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "123456", credential.Key)
+
+	t.Setenv("FOFA_CREDENTIAL", "lab")
+	credential, ok, err = storedCredential()
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "654321", credential.Key)
+	t.Setenv("FOFA_CREDENTIAL", "none")
+	_, ok, err = storedCredential()
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	// 口令模式未设置口令时忽略
+	t.Setenv("FOFA_CREDENTIAL", "lab")
+	store.Passphrase = "secret"
+	assert.Nil(t, store.Set("lab", Credential{Key: "654321"}))
+	_, ok, err = storedCredential()
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	t.Setenv("FOFA_PASSPHRASE", "secret")
+	credential, ok, err = storedCredential()
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "654321", credential.Key)
+	t.Setenv("FOFA_PASSPHRASE", "wrong")
+	_, _, err = storedCredential()
+	assert.ErrorIs(t, err, ErrCredentialDecrypt)
+}
@@ -5,9 +5,12 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
-	"github.com/Knetic/govaluate"
+	"github.com/FofaInfo/GoFOFA/pkg/dedup"
+	"github.com/FofaInfo/GoFOFA/pkg/outformats"
 	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
 	"math"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -47,14 +50,19 @@ type HostStatsData struct {
 
 // SearchOptions options of search, for post processors
 type SearchOptions struct {
-	FixUrl      bool   // each host fix as url, like 1.1.1.1,80 will change to http://1.1.1.1, https://1.1.1.1:8443 will no change
-	UrlPrefix   string // default is http://
-	Full        bool   // search result for over a year
-	UniqByIP    bool   // uniq by ip
-	CheckActive int    // probe website is existed, add isActive field
-	DeWildcard  int    // number of wildcard domains retained
-	Filter      string // filter data by rules
-	DedupHost   bool   // prioritize subdomain data retention
+	FixUrl           bool    `json:"fixUrl,omitempty"`           // each host fix as url, like 1.1.1.1,80 will change to http://1.1.1.1, https://1.1.1.1:8443 will no change
+	UrlPrefix        string  `json:"urlPrefix,omitempty"`        // default is http://
+	Full             bool    `json:"full,omitempty"`             // search result for over a year
+	UniqByIP         bool    `json:"uniqByIP,omitempty"`         // uniq by ip
+	CheckActive      int     `json:"checkActive,omitempty"`      // probe website is existed, add isActive field
+	DeWildcard       int     `json:"deWildcard,omitempty"`       // number of subdomains retained of each wildcard parent domain, add is_wildcard and wildcard_count fields
+	WildcardMin      int     `json:"wildcardMin,omitempty"`      // count of subdomains sharing the same ip set, title and body hash to detect wildcard, default is 5
+	Filter           string  `json:"filter,omitempty"`           // filter data by rules
+	DedupHost        bool    `json:"dedupHost,omitempty"`        // prioritize subdomain data retention
+	Similar          string  `json:"similar,omitempty"`          // keep one of each cluster of near-duplicates by simhash/minhash of body/title/header, add cluster_size field
+	SimilarThreshold float64 `json:"similarThreshold,omitempty"` // similarity threshold of near-duplicates, default is 0.9
+
+	OnTotal func(total int) `json:"-"` // called once with the total size of query reported by server
 }
 
 // fixHostToUrl 替换host为url
@@ -65,18 +73,11 @@ func fixHostToUrl(res [][]string, fields []string, hostIndex int, urlPrefix stri
 		newRow := make([]string, 0, len(fields))
 		for j, r := range row {
 			if j == hostIndex {
-				if !strings.Contains(r, "://") {
-					if urlPrefix != "" {
-						r = urlPrefix + r
-					} else if protocolIndex != -1 &&
-						(row[protocolIndex] == "socks5" || row[protocolIndex] == "redis" ||
-							row[protocolIndex] == "http" || row[protocolIndex] == "https" ||
-							row[protocolIndex] == "mongodb" || row[protocolIndex] == "mysql") {
-						r = row[protocolIndex] + "://" + r
-					} else {
-						r = "http://" + r
-					}
+				protocol := ""
+				if protocolIndex != -1 {
+					protocol = row[protocolIndex]
 				}
+				r = outformats.FixURL(r, protocol, urlPrefix)
 			}
 			newRow = append(newRow, r)
 		}
@@ -100,16 +101,6 @@ func getParamIndexThenAdd(fields []string, field string) (int, []string) {
 	return paramIndex, fields
 }
 
-func extractVariables(filter string) ([]string, error) {
-	f, err := govaluate.NewEvaluableExpression(filter)
-	if err != nil {
-		return nil, err
-	}
-
-	variables := f.Vars()
-	return variables, nil
-}
-
 // fixUrlCheck 检查参数，构建新的field和记录相关字段的偏移
 // 返回hostIndex, protocolIndex, fields, rawFieldSize, err
 func (c *Client) fixUrlCheck(fields []string, options ...SearchOptions) (int, int, []string, int, error) {
@@ -186,16 +177,24 @@ func (c *Client) HostSearch(query string, size int, fields []string, options ...
 		uniqByIP    bool
 		checkActive int
 		deWildcard  int
+		wildcardMin int
 		dedupHost   bool
+		similar     string
+		threshold   float64
 		filter      string
+		onTotal     func(int)
 	)
 	if len(options) > 0 {
 		full = options[0].Full
 		uniqByIP = options[0].UniqByIP
 		checkActive = options[0].CheckActive
 		deWildcard = options[0].DeWildcard
+		wildcardMin = options[0].WildcardMin
 		filter = options[0].Filter
 		dedupHost = options[0].DedupHost
+		similar = options[0].Similar
+		threshold = options[0].SimilarThreshold
+		onTotal = options[0].OnTotal
 	}
 
 	freeSize := c.freeSize()
@@ -253,22 +252,31 @@ func (c *Client) HostSearch(query string, size int, fields []string, options ...
 		codeIndex, fields = getParamIndexThenAdd(fields, "status_code")
 	}
 
-	deWildcardMap := make(map[string]int)
-	// 确认fields包含ip、port、domain、title、fid
-	var portIndex, domainIndex, titleIndex, fidIndex int = -1, -1, -1, -1
+	// 确认fields包含host或link、ip、port、title、fid，统计泛解析
+	var detector *dedup.WildcardDetector
 	if deWildcard > 0 {
-		ipIndex, fields = getParamIndexThenAdd(fields, "ip")
-		portIndex, fields = getParamIndexThenAdd(fields, "port")
-		domainIndex, fields = getParamIndexThenAdd(fields, "domain")
-		titleIndex, fields = getParamIndexThenAdd(fields, "title")
-		fidIndex, fields = getParamIndexThenAdd(fields, "fid")
+		if !slices.ContainsFunc(dedup.WildcardHostFields, func(f string) bool { return slices.Contains(fields, f) }) {
+			_, fields = getParamIndexThenAdd(fields, "host")
+		}
+		_, fields = getParamIndexThenAdd(fields, "ip")
+		_, fields = getParamIndexThenAdd(fields, "port")
+		_, fields = getParamIndexThenAdd(fields, "title")
+		if !slices.ContainsFunc(dedup.WildcardPageFields, func(f string) bool { return slices.Contains(fields, f) }) {
+			_, fields = getParamIndexThenAdd(fields, "fid")
+		}
+		detector, err = dedup.NewWildcardDetector(fields, dedup.WildcardOptions{MinSubdomains: wildcardMin, Keep: deWildcard})
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// 过滤器配置
+	// 过滤器配置，规则只编译一次
 	filterIndexs := make(map[string]int)
+	var filterEnv map[string]interface{}
+	var filterProgram *vm.Program
 	if len(filter) > 0 {
 		var variables []string
-		variables, err = extractVariables(filter)
+		variables, err = exprVariables(filter)
 		if err != nil {
 			return nil, err
 		}
@@ -278,6 +286,12 @@ func (c *Client) HostSearch(query string, size int, fields []string, options ...
 			filterIndex, fields = getParamIndexThenAdd(fields, filterField)
 			filterIndexs[filterField] = filterIndex
 		}
+
+		filterEnv = exprEnv(variables)
+		filterProgram, err = compileExpr(filter, filterEnv)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	dedupHostMap := make(map[string][]string)
@@ -288,6 +302,18 @@ func (c *Client) HostSearch(query string, size int, fields []string, options ...
 		linkIndex, fields = getParamIndexThenAdd(fields, "link")
 	}
 
+	// 确认fields包含body、title、header之一，默认title
+	var clusterer *dedup.Clusterer
+	if len(similar) > 0 {
+		if !slices.ContainsFunc(dedup.SimilarFields, func(f string) bool { return slices.Contains(fields, f) }) {
+			_, fields = getParamIndexThenAdd(fields, "title")
+		}
+		clusterer, err = dedup.NewClusterer(fields, dedup.SimilarOptions{Method: similar, Threshold: threshold})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// 分页取数据
 	for {
 		if ctx := c.GetContext(); ctx != nil {
@@ -320,6 +346,10 @@ func (c *Client) HostSearch(query string, size int, fields []string, options ...
 			break
 		}
 
+		if page == 1 && onTotal != nil {
+			onTotal(hr.Size)
+		}
+
 		var results [][]string
 		if v, ok := hr.Results.([]interface{}); ok {
 			// 无数据
@@ -338,26 +368,12 @@ func (c *Client) HostSearch(query string, size int, fields []string, options ...
 						}
 						uniqIPMap[newSlice[ipIndex]] = true
 					}
-					if deWildcard > 0 {
-						key := fmt.Sprintf("%s:%s:%s:%s:%s", newSlice[ipIndex], newSlice[portIndex],
-							newSlice[domainIndex], newSlice[titleIndex], newSlice[fidIndex])
-						if _, ok := deWildcardMap[key]; ok && deWildcardMap[key] > 3 {
-							continue
-						}
-						deWildcardMap[key]++
-					}
 					if len(filter) > 0 {
-						env := make(map[string]interface{})
 						for field, index := range filterIndexs {
-							env[field] = newSlice[index]
+							filterEnv[field] = newSlice[index]
 						}
 
-						program, err := expr.Compile(filter, expr.Env(env))
-						if err != nil {
-							return nil, err
-						}
-
-						match, err := expr.Run(program, env)
+						match, err := expr.Run(filterProgram, filterEnv)
 						if err != nil {
 							return nil, err
 						}
@@ -435,6 +451,18 @@ func (c *Client) HostSearch(query string, size int, fields []string, options ...
 		res = result
 	}
 
+	// 泛解析去重，每个泛解析父域名的子域名保留deWildcard个
+	var wildcardCounts []int
+	if detector != nil {
+		res, activeSlice, wildcardCounts = collapseWildcard(detector, res, activeSlice)
+	}
+
+	// 相似去重，每个相似簇保留第一条
+	var clusterSizes []int
+	if clusterer != nil {
+		res, activeSlice, clusterSizes = clusterSimilar(clusterer, res, activeSlice)
+	}
+
 	// 后处理
 	res = c.postProcess(res, fields, hostIndex, protocolIndex, rawFieldSize, options...)
 	if checkActive > 0 {
@@ -442,10 +470,67 @@ func (c *Client) HostSearch(query string, size int, fields []string, options ...
 			res[index] = append(res[index], activeSlice[index])
 		}
 	}
+	if clusterer != nil {
+		for index := range res {
+			res[index] = append(res[index], strconv.Itoa(clusterSizes[index]))
+		}
+	}
+	if detector != nil {
+		for index := range res {
+			res[index] = append(res[index], strconv.FormatBool(wildcardCounts[index] > 0), strconv.Itoa(max(wildcardCounts[index], 1)))
+		}
+	}
 
 	return
 }
 
+// clusterSimilar keep the first record of each cluster of near-duplicates,
+// returns kept records, their active results if checked, and sizes of their clusters
+func clusterSimilar(clusterer *dedup.Clusterer, res [][]string, activeSlice []string) ([][]string, []string, []int) {
+	var kept [][]string
+	var keptActive []string
+	var ids []int
+	for i, row := range res {
+		id, isNew := clusterer.Add(row)
+		if !isNew {
+			continue
+		}
+		kept = append(kept, row)
+		ids = append(ids, id)
+		if i < len(activeSlice) {
+			keptActive = append(keptActive, activeSlice[i])
+		}
+	}
+	sizes := make([]int, len(ids))
+	for i, id := range ids {
+		sizes[i] = clusterer.Size(id)
+	}
+	return kept, keptActive, sizes
+}
+
+// collapseWildcard keep representatives of subdomains of wildcard parent domains,
+// returns kept records, their active results if checked, and counts of subdomains they represent, 0 if not wildcard
+func collapseWildcard(detector *dedup.WildcardDetector, res [][]string, activeSlice []string) ([][]string, []string, []int) {
+	for _, row := range res {
+		detector.Add(row)
+	}
+	var kept [][]string
+	var keptActive []string
+	var counts []int
+	for i, row := range res {
+		keep, count := detector.Check(row)
+		if !keep {
+			continue
+		}
+		kept = append(kept, row)
+		counts = append(counts, count)
+		if i < len(activeSlice) {
+			keptActive = append(keptActive, activeSlice[i])
+		}
+	}
+	return kept, keptActive, counts
+}
+
 // HostSize fetch query matched host count
 func (c *Client) HostSize(query string) (count int, err error) {
 	var hr HostResults
@@ -483,8 +568,10 @@ func (c *Client) HostStats(host string) (data HostStatsData, err error) {
 // options for search
 func (c *Client) DumpSearch(query string, allSize int, batchSize int, fields []string, onResults func([][]string, int) error, options ...SearchOptions) (err error) {
 	var full bool
+	var onTotal func(int)
 	if len(options) > 0 {
 		full = options[0].Full
+		onTotal = options[0].OnTotal
 	}
 
 	next := ""
@@ -532,6 +619,10 @@ func (c *Client) DumpSearch(query string, allSize int, batchSize int, fields []s
 			break
 		}
 
+		if next == "" && onTotal != nil {
+			onTotal(hr.Size)
+		}
+
 		var results [][]string
 		if v, ok := hr.Results.([]interface{}); ok {
 			// 无数据
@@ -159,10 +159,18 @@ func TestClient_HostSearch(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 100, len(res))
 	res, err = cli.HostSearch("domain=huashunxinan.net", 500, []string{"link"}, SearchOptions{
-		DeWildcard: 3,
+		DeWildcard: 1,
 	})
 	assert.Nil(t, err)
 	assert.Equal(t, 11, len(res))
+	assert.Equal(t, []string{"http://h8huumr2zdmwgy5.huashunxinan.net", "true", "39"}, res[0]) // 多is_wildcard和wildcard_count字段
+	assert.Equal(t, []string{"http://huashunxinan.net", "false", "1"}, res[4])
+	res, err = cli.HostSearch("domain=huashunxinan.net", 500, []string{"link"}, SearchOptions{
+		DeWildcard:  3,
+		WildcardMin: 20,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 28, len(res))
 
 	// subdomain去重
 	res, err = cli.HostSearch("domain=huashunxinan.net", 500, []string{"link"}, SearchOptions{})
@@ -193,6 +201,19 @@ func TestClient_HostSearch(t *testing.T) {
 	})
 	assert.Nil(t, err)
 	assert.Equal(t, 57, len(res))
+	res, err = cli.HostSearch("domain=huashunxinan.net", 500, []string{"ip", "host", "status_code"}, SearchOptions{
+		Filter: "int(status_code) == 200 && inCIDR(ip, '117.50.0.0/16') && domainOf(host) == 'huashunxinan.net'",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 57, len(res))
+	_, err = cli.HostSearch("domain=huashunxinan.net", 500, []string{"ip", "host", "status_code"}, SearchOptions{
+		Filter: "inCIDR(ip)",
+	})
+	assert.Nil(t, err)
+	_, err = cli.HostSearch("domain=huashunxinan.net", 500, []string{"ip", "host", "status_code"}, SearchOptions{
+		Filter: "status_code",
+	})
+	assert.Error(t, err)
 }
 
 func TestClient_HostSearch_UniqIP(t *testing.T) {
@@ -219,6 +240,38 @@ func TestClient_HostSearch_UniqIP(t *testing.T) {
 	assert.Equal(t, "2.2.2.2", res[1][0])
 }
 
+func TestClient_HostSearch_Similar(t *testing.T) {
+	fofaQueryTest := `title="parked"`
+	ts := httptest.NewServer(http.HandlerFunc(bindSearchAllQueryHandle(fofaQueryTest, "host,title",
+		`{"error":false,"size":4,"page":1,"mode":"extended","query":"title=\"parked\"","results":[`+
+			`["a.example.com","This domain a.example.com is parked free, courtesy of registrar"],`+
+			`["b.test.com","Welcome to nginx!"],`+
+			`["bb.example.com","This domain bb.example.com is parked free, courtesy of registrar"],`+
+			`["c.example.com","This domain c.example.com is parked free, courtesy of registrar"]]}`,
+	)))
+	defer ts.Close()
+	var cli *Client
+	var err error
+	var account accountInfo
+	var res [][]string
+
+	account = validAccounts[3]
+	cli, err = NewClient(WithURL(ts.URL + "?email=" + account.Email + "&key=" + account.Key))
+
+	// title is added to compare
+	res, err = cli.HostSearch(fofaQueryTest, 10, []string{"host"}, SearchOptions{
+		Similar:          "minhash",
+		SimilarThreshold: 0.7,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"a.example.com", "3"}, {"b.test.com", "1"}}, res)
+
+	_, err = cli.HostSearch(fofaQueryTest, 10, []string{"host"}, SearchOptions{
+		Similar: "lsh",
+	})
+	assert.Error(t, err)
+}
+
 func TestClient_HostSearch_FixUrl(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(queryHander))
 	defer ts.Close()
@@ -421,3 +474,35 @@ func TestClient_DumpSearch(t *testing.T) {
 	}, SearchOptions{FixUrl: true})
 	assert.NotNil(t, err)
 }
+
+func TestClient_OnTotal(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(queryHander))
+	defer ts.Close()
+
+	account := validAccounts[1]
+	cli, err := NewClient(WithURL(ts.URL + "?email=" + account.Email + "&key=" + account.Key))
+	assert.Nil(t, err)
+
+	// host search
+	var totals []int
+	res, err := cli.HostSearch("port=50000", 10000, nil, SearchOptions{
+		OnTotal: func(total int) {
+			totals = append(totals, total)
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 9, len(res))
+	assert.Equal(t, []int{9}, totals)
+
+	// dump search
+	totals = nil
+	err = cli.DumpSearch("port=80", 10000, 10, []string{"ip", "port"}, func(i [][]string, i2 int) error {
+		return nil
+	}, SearchOptions{
+		OnTotal: func(total int) {
+			totals = append(totals, total)
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(totals))
+}
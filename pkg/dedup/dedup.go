@@ -0,0 +1,246 @@
+// Package dedup removes duplicate records by key fields. Records are sorted externally,
+// so that files larger than memory can be deduplicated.
+package dedup
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// strategies of which record to keep among duplicates
+const (
+	KeepFirst  = "first"  // the first record in input
+	KeepLast   = "last"   // the last record in input
+	KeepNewest = "newest" // the record of the latest TimeField, the first if same
+	KeepPrefer = "prefer" // the record preferred by Prefer rule, the first if same
+)
+
+// Keeps strategies of which record to keep
+var Keeps = []string{KeepFirst, KeepLast, KeepNewest, KeepPrefer}
+
+const (
+	// DefaultTimeField field of time to keep the newest record
+	DefaultTimeField = "lastupdatetime"
+	// DefaultMemoryLimit bytes of records kept in memory before spilling to disk
+	DefaultMemoryLimit = 64 << 20
+)
+
+// Rule prefer rule, records whose Field is the former of Values are preferred,
+// records of other values are the last
+type Rule struct {
+	Field  string
+	Values []string
+}
+
+// ParseRule parse rule like type=subdomain>service, which prefers subdomain over service
+func ParseRule(s string) (Rule, error) {
+	field, values, ok := strings.Cut(s, "=")
+	field = strings.TrimSpace(field)
+	if !ok || len(field) == 0 || len(values) == 0 {
+		return Rule{}, fmt.Errorf("invalid prefer rule: %s, should be like type=subdomain>service", s)
+	}
+	rule := Rule{Field: field}
+	for _, v := range strings.Split(values, ">") {
+		rule.Values = append(rule.Values, strings.TrimSpace(v))
+	}
+	return rule, nil
+}
+
+// rank of value, less is preferred
+func (r Rule) rank(value string) int {
+	if i := slices.Index(r.Values, value); i != -1 {
+		return i
+	}
+	return len(r.Values)
+}
+
+// Options options of Deduper
+type Options struct {
+	Keep        string // strategy of which record to keep, KeepFirst if empty
+	TimeField   string // field of time for KeepNewest, DefaultTimeField if empty
+	Prefer      Rule   // rule for KeepPrefer
+	MemoryLimit int64  // approximate bytes of records kept in memory, DefaultMemoryLimit if 0
+	TempDir     string // dir of temp files, os.TempDir if empty
+}
+
+// Deduper removes duplicate records of the same values of key fields.
+// Records are added in input order, and kept records are returned in input order.
+type Deduper struct {
+	keyIndexes []int
+	better     func(a, b *entry) bool // a is better than b
+	byKey      *sorter                // records sorted by key and seq
+	bySeq      *sorter                // kept records sorted by seq
+	seq        int64
+	unique     int
+}
+
+// New deduper of records of fields, duplicates are records of the same values of keys
+func New(fields []string, keys []string, options ...Options) (*Deduper, error) {
+	var opts Options
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no dedup fields")
+	}
+
+	d := &Deduper{}
+	for _, key := range keys {
+		index := slices.Index(fields, key)
+		if index == -1 {
+			return nil, fmt.Errorf("field '%s' not found in headers", key)
+		}
+		d.keyIndexes = append(d.keyIndexes, index)
+	}
+
+	switch opts.Keep {
+	case KeepFirst, "":
+		d.better = func(a, b *entry) bool { return a.seq < b.seq }
+	case KeepLast:
+		d.better = func(a, b *entry) bool { return a.seq > b.seq }
+	case KeepNewest:
+		timeField := opts.TimeField
+		if len(timeField) == 0 {
+			timeField = DefaultTimeField
+		}
+		index := slices.Index(fields, timeField)
+		if index == -1 {
+			return nil, fmt.Errorf("time field '%s' not found in headers", timeField)
+		}
+		// fofa time like 2006-01-02 15:04:05 is ordered as text
+		d.better = func(a, b *entry) bool {
+			if a.record[index] != b.record[index] {
+				return a.record[index] > b.record[index]
+			}
+			return a.seq < b.seq
+		}
+	case KeepPrefer:
+		rule := opts.Prefer
+		index := slices.Index(fields, rule.Field)
+		if index == -1 {
+			return nil, fmt.Errorf("prefer field '%s' not found in headers", rule.Field)
+		}
+		d.better = func(a, b *entry) bool {
+			ra, rb := rule.rank(a.record[index]), rule.rank(b.record[index])
+			if ra != rb {
+				return ra < rb
+			}
+			return a.seq < b.seq
+		}
+	default:
+		return nil, fmt.Errorf("unknown keep strategy: %s, can be %s", opts.Keep, strings.Join(Keeps, "/"))
+	}
+
+	limit := opts.MemoryLimit
+	if limit <= 0 {
+		limit = DefaultMemoryLimit
+	}
+	// records of both sorters may be in memory at the same time
+	limit = max(limit/2, 1)
+	d.byKey = &sorter{
+		less: func(a, b *entry) bool {
+			if a.key != b.key {
+				return a.key < b.key
+			}
+			return a.seq < b.seq
+		},
+		compact: d.compact,
+		limit:   limit,
+		dir:     opts.TempDir,
+	}
+	d.bySeq = &sorter{
+		less:  func(a, b *entry) bool { return a.seq < b.seq },
+		limit: limit,
+		dir:   opts.TempDir,
+	}
+	return d, nil
+}
+
+// compact keep the best entry of each key of sorted entries
+func (d *Deduper) compact(sorted []*entry) []*entry {
+	var result []*entry
+	for _, e := range sorted {
+		if n := len(result); n > 0 && result[n-1].key == e.key {
+			if d.better(e, result[n-1]) {
+				result[n-1] = e
+			}
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+// Add record in input order
+func (d *Deduper) Add(record []string) error {
+	keyParts := make([]string, len(d.keyIndexes))
+	for i, index := range d.keyIndexes {
+		keyParts[i] = record[index]
+	}
+	e := &entry{
+		key:    strings.Join(keyParts, "\x00"),
+		seq:    d.seq,
+		record: record,
+	}
+	d.seq++
+	return d.byKey.add(e)
+}
+
+// Each call fn with kept records in input order, it can only be called once after all records are added
+func (d *Deduper) Each(fn func(record []string) error) error {
+	var best *entry
+	err := d.byKey.each(func(e *entry) error {
+		if best != nil && best.key == e.key {
+			if d.better(e, best) {
+				best = e
+			}
+			return nil
+		}
+		if best != nil {
+			d.unique++
+			if err := d.bySeq.add(best); err != nil {
+				return err
+			}
+		}
+		best = e
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if best != nil {
+		d.unique++
+		if err = d.bySeq.add(best); err != nil {
+			return err
+		}
+	}
+	// runs of records by key are not needed anymore
+	if err = d.byKey.close(); err != nil {
+		return err
+	}
+
+	return d.bySeq.each(func(e *entry) error {
+		return fn(e.record)
+	})
+}
+
+// Total count of records added
+func (d *Deduper) Total() int {
+	return int(d.seq)
+}
+
+// Unique count of kept records, valid after Each
+func (d *Deduper) Unique() int {
+	return d.unique
+}
+
+// Close remove temp files
+func (d *Deduper) Close() error {
+	err := d.byKey.close()
+	if e := d.bySeq.close(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+package dedup
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func dedupAll(t *testing.T, fields []string, keys []string, records [][]string, opts Options) [][]string {
+	d, err := New(fields, keys, opts)
+	assert.Nil(t, err)
+	defer d.Close()
+	for _, record := range records {
+		assert.Nil(t, d.Add(record))
+	}
+	var result [][]string
+	assert.Nil(t, d.Each(func(record []string) error {
+		result = append(result, record)
+		return nil
+	}))
+	assert.Equal(t, len(records), d.Total())
+	assert.Equal(t, len(result), d.Unique())
+	return result
+}
+
+func TestDeduper(t *testing.T) {
+	fields := []string{"link", "type", "lastupdatetime"}
+	records := [][]string{
+		{"a.com", "service", "2024-01-02 00:00:00"},
+		{"b.com", "subdomain", "2024-01-01 00:00:00"},
+		{"a.com", "subdomain", "2024-01-01 00:00:00"},
+		{"c.com", "service", ""},
+		{"a.com", "service", "2024-01-03 00:00:00"},
+		{"b.com", "service", "2024-01-02 00:00:00"},
+	}
+
+	for _, limit := range []int64{0, 1, 300} {
+		tempDir := t.TempDir()
+		opts := Options{MemoryLimit: limit, TempDir: tempDir}
+
+		assert.Equal(t, [][]string{records[0], records[1], records[3]},
+			dedupAll(t, fields, []string{"link"}, records, opts))
+
+		opts.Keep = KeepLast
+		assert.Equal(t, [][]string{records[3], records[4], records[5]},
+			dedupAll(t, fields, []string{"link"}, records, opts))
+
+		opts.Keep = KeepNewest
+		assert.Equal(t, [][]string{records[3], records[4], records[5]},
+			dedupAll(t, fields, []string{"link"}, records, opts))
+
+		opts.Keep = KeepPrefer
+		opts.Prefer, _ = ParseRule("type=subdomain>service")
+		assert.Equal(t, [][]string{records[1], records[2], records[3]},
+			dedupAll(t, fields, []string{"link"}, records, opts))
+
+		// multiple keys
+		opts.Keep = KeepFirst
+		assert.Equal(t, [][]string{records[0], records[1], records[2], records[3], records[5]},
+			dedupAll(t, fields, []string{"link", "type"}, records, opts))
+
+		// temp files are removed
+		entries, err := os.ReadDir(tempDir)
+		assert.Nil(t, err)
+		assert.Empty(t, entries)
+	}
+}
+
+func TestDeduper_Spill(t *testing.T) {
+	// values with line breaks and separators are kept as is
+	records := [][]string{
+		{"a", "x\r\ny"},
+		{"a\x00b", "1"},
+		{"a", "2"},
+		{"", "\"3\""},
+		{"a\x00b", "4"},
+	}
+	assert.Equal(t, [][]string{records[0], records[1], records[3]},
+		dedupAll(t, []string{"k", "v"}, []string{"k"}, records, Options{MemoryLimit: 1, TempDir: t.TempDir()}))
+
+	assert.Empty(t, dedupAll(t, []string{"k"}, []string{"k"}, nil, Options{}))
+}
+
+func TestNew(t *testing.T) {
+	fields := []string{"ip", "port"}
+	_, err := New(fields, nil)
+	assert.Error(t, err)
+	_, err = New(fields, []string{"host"})
+	assert.ErrorContains(t, err, "host")
+	_, err = New(fields, []string{"ip"}, Options{Keep: KeepNewest})
+	assert.ErrorContains(t, err, "lastupdatetime")
+	_, err = New(fields, []string{"ip"}, Options{Keep: KeepPrefer, Prefer: Rule{Field: "type"}})
+	assert.ErrorContains(t, err, "type")
+	_, err = New(fields, []string{"ip"}, Options{Keep: "random"})
+	assert.ErrorContains(t, err, "random")
+
+	_, err = ParseRule("subdomain")
+	assert.Error(t, err)
+	rule, err := ParseRule("type = subdomain > service")
+	assert.Nil(t, err)
+	assert.Equal(t, Rule{Field: "type", Values: []string{"subdomain", "service"}}, rule)
+}
@@ -0,0 +1,340 @@
+package dedup
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+)
+
+// mergeFanIn default max runs merged at once, which bounds open files and read buffers
+const mergeFanIn = 64
+
+// entry record with its dedup key and sequence in input
+type entry struct {
+	key    string
+	seq    int64
+	record []string
+}
+
+// size estimated memory of entry
+func (e *entry) size() int64 {
+	n := int64(len(e.key)) + 64
+	for _, v := range e.record {
+		n += int64(len(v)) + 16
+	}
+	return n
+}
+
+// writeString write length prefixed string
+func writeString(w *bufio.Writer, s string) error {
+	var b [binary.MaxVarintLen64]byte
+	if _, err := w.Write(b[:binary.PutUvarint(b[:], uint64(len(s)))]); err != nil {
+		return err
+	}
+	_, err := w.WriteString(s)
+	return err
+}
+
+// writeEntry write entry as key, seq, count of values and values
+func writeEntry(w *bufio.Writer, e *entry) error {
+	var b [binary.MaxVarintLen64]byte
+	if err := writeString(w, e.key); err != nil {
+		return err
+	}
+	if _, err := w.Write(b[:binary.PutVarint(b[:], e.seq)]); err != nil {
+		return err
+	}
+	if _, err := w.Write(b[:binary.PutUvarint(b[:], uint64(len(e.record)))]); err != nil {
+		return err
+	}
+	for _, v := range e.record {
+		if err := writeString(w, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readString read length prefixed string
+func readString(r *bufio.Reader) (string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, n)
+	if _, err = io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// readEntry read entry written by writeEntry, io.EOF at the end
+func readEntry(r *bufio.Reader) (*entry, error) {
+	key, err := readString(r)
+	if err != nil {
+		return nil, err
+	}
+	e := &entry{key: key}
+	if e.seq, err = binary.ReadVarint(r); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	e.record = make([]string, n)
+	for i := range e.record {
+		if e.record[i], err = readString(r); err != nil {
+			return nil, io.ErrUnexpectedEOF
+		}
+	}
+	return e, nil
+}
+
+// sorter external merge sorter, entries are kept in memory until limit is reached,
+// then sorted and spilled to a temp file as a run, runs are merged when iterating
+type sorter struct {
+	less    func(a, b *entry) bool
+	compact func(sorted []*entry) []*entry // reduce sorted entries before spilling, optional
+	limit   int64                          // max bytes of entries in memory
+	dir     string                         // dir of temp files
+	fanIn   int                            // max runs merged at once, mergeFanIn if less than 2
+
+	buf   []*entry
+	bytes int64
+	runs  []string // temp files of sorted runs
+}
+
+// add entry, spill entries to disk if limit is reached
+func (s *sorter) add(e *entry) error {
+	s.buf = append(s.buf, e)
+	s.bytes += e.size()
+	if s.bytes >= s.limit {
+		return s.spill()
+	}
+	return nil
+}
+
+// sorted entries of buf, compacted
+func (s *sorter) sorted() []*entry {
+	sort.SliceStable(s.buf, func(i, j int) bool {
+		return s.less(s.buf[i], s.buf[j])
+	})
+	if s.compact != nil {
+		s.buf = s.compact(s.buf)
+	}
+	return s.buf
+}
+
+// spill write sorted entries of buf to a temp file
+func (s *sorter) spill() (err error) {
+	f, err := os.CreateTemp(s.dir, "fofa-dedup-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file failed: %w", err)
+	}
+	s.runs = append(s.runs, f.Name())
+	defer func() {
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	bw := bufio.NewWriterSize(f, 1<<20)
+	for _, e := range s.sorted() {
+		if err = writeEntry(bw, e); err != nil {
+			return fmt.Errorf("write temp file failed: %w", err)
+		}
+	}
+	if err = bw.Flush(); err != nil {
+		return fmt.Errorf("write temp file failed: %w", err)
+	}
+	s.buf = nil
+	s.bytes = 0
+	return nil
+}
+
+// run reader of a sorted run, either a temp file or entries in memory
+type run struct {
+	f       *os.File
+	r       *bufio.Reader
+	entries []*entry
+	head    *entry
+}
+
+// next advance head of run, head is nil at the end
+func (r *run) next() error {
+	if r.r == nil {
+		r.head = nil
+		if len(r.entries) > 0 {
+			r.head = r.entries[0]
+			r.entries = r.entries[1:]
+		}
+		return nil
+	}
+	e, err := readEntry(r.r)
+	if err == io.EOF {
+		r.head = nil
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read temp file %s failed: %w", r.f.Name(), err)
+	}
+	r.head = e
+	return nil
+}
+
+// runHeap min heap of runs by head
+type runHeap struct {
+	runs []*run
+	less func(a, b *entry) bool
+}
+
+func (h *runHeap) Len() int           { return len(h.runs) }
+func (h *runHeap) Less(i, j int) bool { return h.less(h.runs[i].head, h.runs[j].head) }
+func (h *runHeap) Swap(i, j int)      { h.runs[i], h.runs[j] = h.runs[j], h.runs[i] }
+func (h *runHeap) Push(x interface{}) { h.runs = append(h.runs, x.(*run)) }
+func (h *runHeap) Pop() interface{} {
+	old := h.runs
+	r := old[len(old)-1]
+	h.runs = old[:len(old)-1]
+	return r
+}
+
+// openRuns open readers of temp files of sorted runs
+func openRuns(names []string) ([]*run, error) {
+	var runs []*run
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			for _, r := range runs {
+				r.f.Close()
+			}
+			return nil, err
+		}
+		runs = append(runs, &run{f: f, r: bufio.NewReaderSize(f, 64<<10)})
+	}
+	return runs, nil
+}
+
+// merge call fn with all entries of runs in order, temp files of runs are closed at the end
+func (s *sorter) merge(runs []*run, fn func(e *entry) error) error {
+	defer func() {
+		for _, r := range runs {
+			if r.f != nil {
+				r.f.Close()
+			}
+		}
+	}()
+
+	h := &runHeap{less: s.less}
+	for _, r := range runs {
+		if err := r.next(); err != nil {
+			return err
+		}
+		if r.head != nil {
+			h.runs = append(h.runs, r)
+		}
+	}
+	heap.Init(h)
+	for h.Len() > 0 {
+		r := h.runs[0]
+		if err := fn(r.head); err != nil {
+			return err
+		}
+		if err := r.next(); err != nil {
+			return err
+		}
+		if r.head == nil {
+			heap.Pop(h)
+		} else {
+			heap.Fix(h, 0)
+		}
+	}
+	return nil
+}
+
+// mergeRuns merge the first n spilled runs into a new run at the end, and remove their temp files
+func (s *sorter) mergeRuns(n int) (err error) {
+	names := slices.Clone(s.runs[:n])
+	runs, err := openRuns(names)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(s.dir, "fofa-dedup-*.tmp")
+	if err != nil {
+		for _, r := range runs {
+			r.f.Close()
+		}
+		return fmt.Errorf("create temp file failed: %w", err)
+	}
+	// 先记录，出错时由close删除
+	s.runs = append(s.runs, f.Name())
+	defer func() {
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	bw := bufio.NewWriterSize(f, 1<<20)
+	if err = s.merge(runs, func(e *entry) error {
+		if err := writeEntry(bw, e); err != nil {
+			return fmt.Errorf("write temp file failed: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err = bw.Flush(); err != nil {
+		return fmt.Errorf("write temp file failed: %w", err)
+	}
+
+	s.runs = s.runs[n:]
+	for _, name := range names {
+		if err = os.Remove(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// each call fn with all entries in order, merging spilled runs and entries in memory.
+// Runs are merged in passes of at most fanIn runs, so that not all temp files are open at once
+func (s *sorter) each(fn func(e *entry) error) error {
+	fanIn := s.fanIn
+	if fanIn < 2 {
+		fanIn = mergeFanIn
+	}
+	// 最后一次合并还包括内存中的记录
+	for len(s.runs)+1 > fanIn {
+		// 每次合并k个减少k-1个，不多合并
+		if err := s.mergeRuns(min(fanIn, len(s.runs)+2-fanIn)); err != nil {
+			return err
+		}
+	}
+
+	runs, err := openRuns(s.runs)
+	if err != nil {
+		return err
+	}
+	runs = append(runs, &run{entries: s.sorted()})
+	return s.merge(runs, fn)
+}
+
+// close remove temp files
+func (s *sorter) close() error {
+	var err error
+	for _, name := range s.runs {
+		if e := os.Remove(name); e != nil && err == nil {
+			err = e
+		}
+	}
+	s.runs = nil
+	s.buf = nil
+	return err
+}
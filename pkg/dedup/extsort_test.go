@@ -0,0 +1,48 @@
+package dedup
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSorter_FanIn(t *testing.T) {
+	dir := t.TempDir()
+	s := &sorter{
+		less:  func(a, b *entry) bool { return a.seq < b.seq },
+		limit: 1,
+		dir:   dir,
+		fanIn: 3,
+	}
+	defer s.close()
+
+	// 每条记录一个run，远多于fanIn
+	const n = 20
+	for _, i := range rand.Perm(n) {
+		assert.Nil(t, s.add(&entry{seq: int64(i), record: []string{string(rune('a' + i))}}))
+	}
+	assert.Len(t, s.runs, n)
+
+	var seqs []int64
+	assert.Nil(t, s.each(func(e *entry) error {
+		assert.Equal(t, string(rune('a'+e.seq)), e.record[0])
+		seqs = append(seqs, e.seq)
+		return nil
+	}))
+	for i := range seqs {
+		assert.Equal(t, int64(i), seqs[i])
+	}
+	assert.Len(t, seqs, n)
+	// 最后一次合并的run和内存中的记录不超过fanIn
+	assert.Len(t, s.runs, s.fanIn-1)
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Len(t, entries, s.fanIn-1)
+
+	assert.Nil(t, s.close())
+	entries, err = os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,313 @@
+package dedup
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// methods of near-duplicate detection
+const (
+	SimilarSimHash = "simhash" // 64 bits simhash, similarity is the cosine of shingles estimated by different bits
+	SimilarMinHash = "minhash" // minhash signature, similarity is the estimated jaccard of shingles
+)
+
+// SimilarMethods methods of near-duplicate detection
+var SimilarMethods = []string{SimilarSimHash, SimilarMinHash}
+
+// SimilarFields default fields compared for near-duplicates, those exist in fields are used
+var SimilarFields = []string{"body", "title", "header"}
+
+const (
+	// DefaultSimilarThreshold default similarity threshold of near-duplicates
+	DefaultSimilarThreshold = 0.9
+	// ClusterSizeField field of size of cluster that the kept record represents
+	ClusterSizeField = "cluster_size"
+
+	shingleSize = 4   // runes of each shingle
+	minHashSize = 128 // hashes of minhash signature
+)
+
+// mix64 finalizer of splitmix64, spreads bits of hash
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+// normalize lower text and collapse spaces, so that formatting changes are ignored
+func normalize(text string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.TrimSpace(text) {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// shingles call fn with hash of each shingle of runes of normalized text,
+// text shorter than a shingle is a shingle itself
+func shingles(text string, fn func(h uint64)) {
+	text = normalize(text)
+	if len(text) == 0 {
+		return
+	}
+	// byte offsets of runes
+	offsets := make([]int, 0, len(text)+1)
+	for i := range text {
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(text))
+
+	n := len(offsets) - 1 - shingleSize + 1
+	if n < 1 {
+		n = 1
+	}
+	for i := 0; i < n; i++ {
+		end := offsets[min(i+shingleSize, len(offsets)-1)]
+		// fnv-1a
+		h := uint64(14695981039346656037)
+		for _, c := range []byte(text[offsets[i]:end]) {
+			h ^= uint64(c)
+			h *= 1099511628211
+		}
+		fn(mix64(h))
+	}
+}
+
+// SimHash 64 bits simhash of shingles of text
+func SimHash(text string) uint64 {
+	var v [64]int
+	shingles(text, func(h uint64) {
+		for i := 0; i < 64; i++ {
+			if h&(1<<i) != 0 {
+				v[i]++
+			} else {
+				v[i]--
+			}
+		}
+	})
+	var hash uint64
+	for i := 0; i < 64; i++ {
+		if v[i] > 0 {
+			hash |= 1 << i
+		}
+	}
+	return hash
+}
+
+// MinHash minhash signature of shingles of text, nil if text is empty
+func MinHash(text string) []uint32 {
+	set := make(map[uint64]struct{})
+	shingles(text, func(h uint64) {
+		set[h] = struct{}{}
+	})
+	if len(set) == 0 {
+		return nil
+	}
+	sig := make([]uint32, minHashSize)
+	for i := range sig {
+		sig[i] = math.MaxUint32
+	}
+	for h := range set {
+		for i := range sig {
+			if v := uint32(mix64(h+uint64(i+1)*0x9e3779b97f4a7c15) >> 32); v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig
+}
+
+// SimilarOptions options of Clusterer
+type SimilarOptions struct {
+	Method    string   // SimilarSimHash or SimilarMinHash, simhash if empty
+	Fields    []string // fields compared, SimilarFields which exist if empty
+	Threshold float64  // similarity threshold in (0, 1], DefaultSimilarThreshold if 0
+}
+
+// cluster representative of near-duplicates
+type cluster struct {
+	simhash uint64
+	minhash []uint32
+	size    int
+}
+
+// Clusterer clusters near-duplicate records online: each record joins the first cluster
+// whose representative is similar enough, or starts a new cluster as its representative.
+// Candidates are found by bands of hashes (LSH), so memory is proportional to the count of clusters.
+// Records whose compared fields are all empty are not clustered.
+type Clusterer struct {
+	method    string
+	indexes   []int
+	threshold float64
+	maxDist   int // max different bits of simhash
+	bands     int // bands of hash
+	rows      int // rows of each band of minhash
+
+	clusters []cluster
+	index    map[uint64][]int32 // band key to clusters
+}
+
+// NewClusterer clusterer of records of fields
+func NewClusterer(fields []string, opts SimilarOptions) (*Clusterer, error) {
+	c := &Clusterer{
+		method:    opts.Method,
+		threshold: opts.Threshold,
+		index:     make(map[uint64][]int32),
+	}
+	if len(c.method) == 0 {
+		c.method = SimilarSimHash
+	}
+	if c.threshold == 0 {
+		c.threshold = DefaultSimilarThreshold
+	}
+	if c.threshold < 0 || c.threshold > 1 {
+		return nil, fmt.Errorf("similar threshold should be in (0, 1]: %v", c.threshold)
+	}
+
+	compared := opts.Fields
+	if len(compared) == 0 {
+		for _, field := range SimilarFields {
+			if slices.Contains(fields, field) {
+				compared = append(compared, field)
+			}
+		}
+		if len(compared) == 0 {
+			return nil, errors.New("no field to compare, need one of " + strings.Join(SimilarFields, "/"))
+		}
+	}
+	for _, field := range compared {
+		index := slices.Index(fields, field)
+		if index == -1 {
+			return nil, fmt.Errorf("similar field '%s' not found in headers", field)
+		}
+		c.indexes = append(c.indexes, index)
+	}
+
+	switch c.method {
+	case SimilarSimHash:
+		// ratio of different bits of simhash estimates angle/π of vectors of shingles,
+		// and similar hashes have same bits in at least one of maxDist+1 bands
+		c.maxDist = int(64 * math.Acos(c.threshold) / math.Pi)
+		c.bands = min(c.maxDist+1, 64)
+	case SimilarMinHash:
+		// the most rows of band whose threshold of LSH is well below threshold, for recall
+		c.rows = 1
+		for r := 2; r <= minHashSize/2; r *= 2 {
+			if math.Pow(1/float64(minHashSize/r), 1/float64(r)) <= c.threshold-0.1 {
+				c.rows = r
+			}
+		}
+		c.bands = minHashSize / c.rows
+	default:
+		return nil, fmt.Errorf("unknown similar method: %s, can be %s", c.method, strings.Join(SimilarMethods, "/"))
+	}
+	return c, nil
+}
+
+// text compared of record
+func (c *Clusterer) text(record []string) string {
+	values := make([]string, len(c.indexes))
+	for i, index := range c.indexes {
+		values[i] = record[index]
+	}
+	return strings.Join(values, "\n")
+}
+
+// bandKeys keys of bands of hashes
+func (c *Clusterer) bandKeys(cl *cluster) []uint64 {
+	keys := make([]uint64, c.bands)
+	for b := range keys {
+		var h uint64
+		if cl.minhash != nil {
+			for _, v := range cl.minhash[b*c.rows : (b+1)*c.rows] {
+				h = mix64(h ^ uint64(v))
+			}
+		} else {
+			// bits of band b
+			start, end := b*64/c.bands, (b+1)*64/c.bands
+			h = cl.simhash >> start & (1<<(end-start) - 1)
+		}
+		keys[b] = mix64(h + uint64(b)*0x9e3779b97f4a7c15)
+	}
+	return keys
+}
+
+// similar whether hashes of clusters are similar enough
+func (c *Clusterer) similar(a, b *cluster) bool {
+	if a.minhash != nil {
+		same := 0
+		for i, v := range a.minhash {
+			if v == b.minhash[i] {
+				same++
+			}
+		}
+		return float64(same)/minHashSize >= c.threshold
+	}
+	return bits.OnesCount64(a.simhash^b.simhash) <= c.maxDist
+}
+
+// Add record, returns its cluster, and whether it is the representative of a new cluster
+func (c *Clusterer) Add(record []string) (int, bool) {
+	text := c.text(record)
+	var cl cluster
+	if c.method == SimilarMinHash {
+		cl.minhash = MinHash(text)
+		if cl.minhash == nil {
+			return c.newCluster(cl, nil), true
+		}
+	} else {
+		if len(normalize(text)) == 0 {
+			return c.newCluster(cl, nil), true
+		}
+		cl.simhash = SimHash(text)
+	}
+
+	keys := c.bandKeys(&cl)
+	for _, key := range keys {
+		for _, id := range c.index[key] {
+			if c.similar(&cl, &c.clusters[id]) {
+				c.clusters[id].size++
+				return int(id), false
+			}
+		}
+	}
+	return c.newCluster(cl, keys), true
+}
+
+// newCluster add cluster of record, indexed by keys
+func (c *Clusterer) newCluster(cl cluster, keys []uint64) int {
+	id := len(c.clusters)
+	cl.size = 1
+	c.clusters = append(c.clusters, cl)
+	for _, key := range keys {
+		c.index[key] = append(c.index[key], int32(id))
+	}
+	return id
+}
+
+// Size count of records of cluster
+func (c *Clusterer) Size(id int) int {
+	return c.clusters[id].size
+}
+
+// Len count of clusters
+func (c *Clusterer) Len() int {
+	return len(c.clusters)
+}
@@ -0,0 +1,77 @@
+package dedup
+
+import (
+	"fmt"
+	"math/bits"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parkedPage(host string) string {
+	return fmt.Sprintf("<html><head><title>Parked domain %s</title></head><body>This domain %s is parked free, "+
+		"courtesy of registrar. Buy this domain now and get started with hosting, email and more services today.</body></html>", host, host)
+}
+
+const nginxPage = "<html><head><title>Welcome to nginx</title></head><body>If you see this page, " +
+	"the nginx web server is successfully installed and working.</body></html>"
+
+func TestSimHash(t *testing.T) {
+	assert.Equal(t, SimHash("Hello  World"), SimHash("hello world"))
+	assert.Less(t, bits.OnesCount64(SimHash(parkedPage("a1.example.com"))^SimHash(parkedPage("s2b.example.com"))), 10)
+	assert.Greater(t, bits.OnesCount64(SimHash(parkedPage("a1.example.com"))^SimHash(nginxPage)), 20)
+	assert.Equal(t, uint64(0), SimHash(""))
+}
+
+func TestMinHash(t *testing.T) {
+	assert.Nil(t, MinHash(" "))
+	assert.Equal(t, MinHash("ab"), MinHash("AB"))
+	assert.Len(t, MinHash("ab"), minHashSize)
+}
+
+func TestClusterer(t *testing.T) {
+	fields := []string{"host", "title", "body"}
+	records := [][]string{
+		{"a1.example.com", "Parked domain a1.example.com", parkedPage("a1.example.com")},
+		{"nginx.com", "Welcome to nginx", nginxPage},
+		{"s2b.example.com", "Parked domain s2b.example.com", parkedPage("s2b.example.com")},
+		{"empty1.com", "", ""},
+		{"c3.example.com", "Parked domain c3.example.com", parkedPage("c3.example.com")},
+		{"empty2.com", "", ""},
+	}
+	// simhash is less accurate for short text
+	thresholds := map[string]float64{SimilarSimHash: 0.7, SimilarMinHash: 0.8}
+	for _, method := range SimilarMethods {
+		c, err := NewClusterer(fields, SimilarOptions{Method: method, Threshold: thresholds[method]})
+		assert.Nil(t, err)
+		var ids []int
+		var reps []bool
+		for _, record := range records {
+			id, isNew := c.Add(record)
+			ids = append(ids, id)
+			reps = append(reps, isNew)
+		}
+		assert.Equal(t, []int{0, 1, 0, 2, 0, 3}, ids, method)
+		assert.Equal(t, []bool{true, true, false, true, false, true}, reps, method)
+		assert.Equal(t, 4, c.Len())
+		assert.Equal(t, 3, c.Size(0))
+		assert.Equal(t, 1, c.Size(3))
+
+		// compare host only
+		c, err = NewClusterer(fields, SimilarOptions{Method: method, Fields: []string{"host"}, Threshold: 1})
+		assert.Nil(t, err)
+		for _, record := range records {
+			c.Add(record)
+		}
+		assert.Equal(t, len(records), c.Len())
+	}
+
+	_, err := NewClusterer([]string{"ip"}, SimilarOptions{})
+	assert.Error(t, err)
+	_, err = NewClusterer(fields, SimilarOptions{Fields: []string{"header"}})
+	assert.ErrorContains(t, err, "header")
+	_, err = NewClusterer(fields, SimilarOptions{Threshold: 1.5})
+	assert.Error(t, err)
+	_, err = NewClusterer(fields, SimilarOptions{Method: "lsh"})
+	assert.ErrorContains(t, err, "lsh")
+}
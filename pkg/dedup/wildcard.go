@@ -0,0 +1,262 @@
+package dedup
+
+import (
+	"errors"
+	"hash/fnv"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/weppos/publicsuffix-go/publicsuffix"
+)
+
+const (
+	// DefaultWildcardMin default count of subdomains of a parent domain sharing the same
+	// ip set, title and body hash to detect the parent as wildcard
+	DefaultWildcardMin = 5
+	// IsWildcardField field of whether the record represents subdomains of a wildcard parent domain
+	IsWildcardField = "is_wildcard"
+	// WildcardCountField field of count of subdomains the record represents
+	WildcardCountField = "wildcard_count"
+)
+
+// WildcardHostFields fields of hostname, the first exists is used
+var WildcardHostFields = []string{"host", "link"}
+
+// WildcardPageFields fields of page hash, the first exists is used
+var WildcardPageFields = []string{"body", "fid"}
+
+// WildcardOptions options of WildcardDetector
+type WildcardOptions struct {
+	MinSubdomains int // count of subdomains sharing the same signature to detect wildcard, DefaultWildcardMin if 0
+	Keep          int // subdomains kept of each wildcard parent domain, 1 if 0
+}
+
+// subdomain signature parts of a subdomain, collected from all its records
+type subdomain struct {
+	parents []string // parent domains up to the registrable domain, the nearest first
+	seq     int      // order of the first record
+	ips     []string // sorted ip set
+	pages   []uint64 // sorted hashes of port, title and body
+	group   *wildcardGroup
+}
+
+// wildcardGroup subdomains of a wildcard parent domain sharing the same signature
+type wildcardGroup struct {
+	parent string
+	size   int
+	kept   []string // representative subdomains
+}
+
+// WildcardDetector detects wildcard dns: subdomains of a parent domain resolved by a wildcard record
+// share the same ip set, title and body, so that a parent domain is wildcard if many of its subdomains
+// share the same signature, and those subdomains are collapsed into representatives.
+// A wildcard record matches subdomains of any depth, so subdomains are grouped by each of their
+// parent domains, and belong to the highest wildcard one.
+// Records are added in the first pass, and checked in the same order in the second pass,
+// memory is proportional to the count of subdomains.
+type WildcardDetector struct {
+	hostIndex  int
+	ipIndex    int
+	titleIndex int
+	portIndex  int // -1 if not exists
+	pageIndex  int // -1 if not exists
+	min        int
+	keep       int
+
+	subdomains map[string]*subdomain
+	parents    []string
+	detected   bool
+}
+
+// NewWildcardDetector detector of records of fields, which should contain host or link, ip and title,
+// and body or fid and port if exist
+func NewWildcardDetector(fields []string, opts WildcardOptions) (*WildcardDetector, error) {
+	d := &WildcardDetector{
+		hostIndex:  -1,
+		pageIndex:  -1,
+		ipIndex:    slices.Index(fields, "ip"),
+		titleIndex: slices.Index(fields, "title"),
+		portIndex:  slices.Index(fields, "port"),
+		min:        opts.MinSubdomains,
+		keep:       opts.Keep,
+		subdomains: make(map[string]*subdomain),
+	}
+	for _, field := range WildcardHostFields {
+		if d.hostIndex = slices.Index(fields, field); d.hostIndex != -1 {
+			break
+		}
+	}
+	for _, field := range WildcardPageFields {
+		if d.pageIndex = slices.Index(fields, field); d.pageIndex != -1 {
+			break
+		}
+	}
+	if d.hostIndex == -1 || d.ipIndex == -1 || d.titleIndex == -1 {
+		return nil, errors.New("wildcard detection needs fields of host or link, ip and title")
+	}
+	if d.min <= 0 {
+		d.min = DefaultWildcardMin
+	}
+	if d.keep <= 0 {
+		d.keep = 1
+	}
+	return d, nil
+}
+
+// Hostname lower hostname of host or url, without port
+func Hostname(host string) string {
+	if _, after, found := strings.Cut(host, "://"); found {
+		host = after
+	}
+	if i := strings.IndexAny(host, "/?#"); i != -1 {
+		host = host[:i]
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.Trim(host, "[]"))
+}
+
+// parentsOf parent domains of hostname up to the registrable domain, the nearest first,
+// empty if hostname is an ip or a registrable domain
+func parentsOf(hostname string) []string {
+	if net.ParseIP(hostname) != nil {
+		return nil
+	}
+	domain, err := publicsuffix.Domain(hostname)
+	if err != nil {
+		return nil
+	}
+	var parents []string
+	for parent := hostname; parent != domain; {
+		_, parent, _ = strings.Cut(parent, ".")
+		parents = append(parents, parent)
+	}
+	return parents
+}
+
+// insert value into sorted set
+func insert[T string | uint64](set []T, v T) []T {
+	i, found := slices.BinarySearch(set, v)
+	if found {
+		return set
+	}
+	return slices.Insert(set, i, v)
+}
+
+// Add record in the first pass
+func (d *WildcardDetector) Add(record []string) {
+	hostname := Hostname(record[d.hostIndex])
+	sub, ok := d.subdomains[hostname]
+	if !ok {
+		parents := parentsOf(hostname)
+		if len(parents) == 0 {
+			return
+		}
+		sub = &subdomain{parents: parents, seq: len(d.subdomains)}
+		d.subdomains[hostname] = sub
+	}
+	sub.ips = insert(sub.ips, record[d.ipIndex])
+
+	// pages of wildcard subdomains may contain their own hostname
+	h := fnv.New64a()
+	if d.portIndex != -1 {
+		h.Write([]byte(record[d.portIndex]))
+	}
+	h.Write([]byte{0})
+	h.Write([]byte(strings.ReplaceAll(strings.ToLower(record[d.titleIndex]), hostname, "*")))
+	if d.pageIndex != -1 {
+		h.Write([]byte{0})
+		h.Write([]byte(strings.ReplaceAll(strings.ToLower(record[d.pageIndex]), hostname, "*")))
+	}
+	sub.pages = insert(sub.pages, h.Sum64())
+}
+
+// signature of subdomain, without parent domain
+func (sub *subdomain) signature() string {
+	var b strings.Builder
+	for _, ip := range sub.ips {
+		b.WriteByte(0)
+		b.WriteString(ip)
+	}
+	b.WriteByte(0)
+	for _, page := range sub.pages {
+		b.WriteByte(0)
+		b.WriteString(strconv.FormatUint(page, 16))
+	}
+	return b.String()
+}
+
+// Detect wildcard parent domains after the first pass, returns them sorted
+func (d *WildcardDetector) Detect() []string {
+	if d.detected {
+		return d.parents
+	}
+	d.detected = true
+
+	// subdomains in input order, so that the first ones are representatives
+	hostnames := make([]string, 0, len(d.subdomains))
+	for hostname := range d.subdomains {
+		hostnames = append(hostnames, hostname)
+	}
+	sort.Slice(hostnames, func(i, j int) bool {
+		return d.subdomains[hostnames[i]].seq < d.subdomains[hostnames[j]].seq
+	})
+
+	// groups of each parent domain and signature
+	groups := make(map[string]*wildcardGroup)
+	groupsOf := make(map[string][]*wildcardGroup, len(hostnames))
+	for _, hostname := range hostnames {
+		sub := d.subdomains[hostname]
+		sig := sub.signature()
+		for _, parent := range sub.parents {
+			key := parent + "\x00" + sig
+			group, ok := groups[key]
+			if !ok {
+				group = &wildcardGroup{parent: parent}
+				groups[key] = group
+			}
+			group.size++
+			if len(group.kept) < d.keep {
+				group.kept = append(group.kept, hostname)
+			}
+			groupsOf[hostname] = append(groupsOf[hostname], group)
+		}
+	}
+
+	// subdomains belong to the highest wildcard parent domain, whose group contains those of lower ones
+	parents := make(map[string]bool)
+	for _, hostname := range hostnames {
+		candidates := groupsOf[hostname]
+		for i := len(candidates) - 1; i >= 0; i-- {
+			if group := candidates[i]; group.size >= d.min {
+				d.subdomains[hostname].group = group
+				parents[group.parent] = true
+				break
+			}
+		}
+	}
+	for parent := range parents {
+		d.parents = append(d.parents, parent)
+	}
+	sort.Strings(d.parents)
+	return d.parents
+}
+
+// Check record in the second pass, returns whether to keep it,
+// and count of subdomains it represents if it is a representative of a wildcard parent domain, or 0
+func (d *WildcardDetector) Check(record []string) (bool, int) {
+	d.Detect()
+	hostname := Hostname(record[d.hostIndex])
+	sub, ok := d.subdomains[hostname]
+	if !ok || sub.group == nil {
+		return true, 0
+	}
+	if slices.Contains(sub.group.kept, hostname) {
+		return true, sub.group.size
+	}
+	return false, 0
+}
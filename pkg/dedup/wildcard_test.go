@@ -0,0 +1,76 @@
+package dedup
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostname(t *testing.T) {
+	assert.Equal(t, "a.example.com", Hostname("https://A.example.com:8443/path?q=1"))
+	assert.Equal(t, "a.example.com", Hostname("a.example.com:80"))
+	assert.Equal(t, "a.example.com", Hostname("a.example.com"))
+	assert.Equal(t, "::1", Hostname("http://[::1]:80"))
+	assert.Equal(t, []string{"b.example.com", "example.com"}, parentsOf("a.b.example.com"))
+	assert.Empty(t, parentsOf("example.co.uk"))
+	assert.Empty(t, parentsOf("1.1.1.1"))
+}
+
+func TestWildcardDetector(t *testing.T) {
+	fields := []string{"host", "ip", "port", "title", "body"}
+	var records [][]string
+	// wildcard subdomains of any depth, whose pages contain their hostnames
+	for _, name := range []string{"a1", "b2", "c3.d4", "e5"} {
+		host := name + ".example.com"
+		records = append(records,
+			[]string{host, "1.1.1.1", "80", "Parked domain " + host, parkedPage(host)},
+			[]string{host + ":443", "1.1.1.1", "443", "Parked domain " + host, parkedPage(host)},
+		)
+	}
+	records = append(records,
+		// the registrable domain itself, another page, and another ip
+		[]string{"example.com", "1.1.1.1", "80", "Parked domain example.com", parkedPage("example.com")},
+		[]string{"www.example.com", "1.1.1.1", "80", "Welcome to nginx", nginxPage},
+		[]string{"f6.example.com", "2.2.2.2", "80", "Parked domain f6.example.com", parkedPage("f6.example.com")},
+		// not enough subdomains
+		[]string{"a.test.com", "3.3.3.3", "80", "test", ""},
+		[]string{"b.test.com", "3.3.3.3", "80", "test", ""},
+		[]string{"3.3.3.3:80", "3.3.3.3", "80", "test", ""},
+	)
+
+	check := func(d *WildcardDetector) []string {
+		for _, record := range records {
+			d.Add(record)
+		}
+		var result []string
+		for _, record := range records {
+			if keep, count := d.Check(record); keep {
+				result = append(result, fmt.Sprintf("%s,%d", record[0], count))
+			}
+		}
+		return result
+	}
+
+	d, err := NewWildcardDetector(fields, WildcardOptions{MinSubdomains: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a1.example.com,4", "a1.example.com:443,4", "example.com,0", "www.example.com,0",
+		"f6.example.com,0", "a.test.com,0", "b.test.com,0", "3.3.3.3:80,0"}, check(d))
+	assert.Equal(t, []string{"example.com"}, d.Detect())
+
+	d, err = NewWildcardDetector(fields, WildcardOptions{MinSubdomains: 2, Keep: 2})
+	assert.Nil(t, err)
+	result := check(d)
+	assert.Equal(t, []string{"a1.example.com,4", "a1.example.com:443,4", "b2.example.com,4", "b2.example.com:443,4"}, result[:4])
+	assert.Contains(t, result, "a.test.com,2")
+	assert.Equal(t, []string{"example.com", "test.com"}, d.Detect())
+
+	// not enough subdomains by default
+	d, err = NewWildcardDetector(fields, WildcardOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, check(d), len(records))
+	assert.Empty(t, d.Detect())
+
+	_, err = NewWildcardDetector([]string{"host", "ip"}, WildcardOptions{})
+	assert.Error(t, err)
+}
@@ -1,22 +1,191 @@
 package outformats
 
 import (
-	"encoding/csv"
+	"bufio"
+	"errors"
+	"fmt"
 	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
 )
 
+// CSVQuote quoting style of CSV fields
+type CSVQuote int
+
+const (
+	CSVQuoteMinimal CSVQuote = iota // quote fields which contain delimiter, quote, newline or leading space
+	CSVQuoteAll                     // quote all fields
+	CSVQuoteNone                    // never quote fields
+)
+
+// ParseCSVQuote parse quoting style, can be minimal/all/none
+func ParseCSVQuote(s string) (CSVQuote, error) {
+	switch s {
+	case "", "minimal":
+		return CSVQuoteMinimal, nil
+	case "all":
+		return CSVQuoteAll, nil
+	case "none":
+		return CSVQuoteNone, nil
+	}
+	return CSVQuoteMinimal, fmt.Errorf("unknown csv quote style: %s", s)
+}
+
+// CSVEncodings output encodings of CSV besides utf-8
+var CSVEncodings = map[string]encoding.Encoding{
+	"gbk":     simplifiedchinese.GBK,
+	"gb18030": simplifiedchinese.GB18030,
+}
+
+// CSVDialect dialect of CSV writer
+type CSVDialect struct {
+	Comma    rune     // field delimiter, default is ,
+	Quote    CSVQuote // quoting style
+	UseCRLF  bool     // end lines with \r\n
+	BOM      bool     // write utf-8 BOM at the beginning for Excel
+	Encoding string   // output encoding, empty is utf-8, can be gbk/gb18030
+}
+
 // CSVWriter CSV format writer
 type CSVWriter struct {
-	*csv.Writer
+	dialect CSVDialect
+	w       *bufio.Writer
+	closer  io.Closer // encoder of output encoding, which only holds an incomplete rune
+	started bool
+}
+
+func (w *CSVWriter) fieldNeedsQuotes(field string) bool {
+	switch w.dialect.Quote {
+	case CSVQuoteAll:
+		return true
+	case CSVQuoteNone:
+		return false
+	}
+	if field == "" {
+		return false
+	}
+	if field == `\.` || strings.ContainsRune(field, w.dialect.Comma) || strings.ContainsAny(field, "\"\r\n") {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(field)
+	return unicode.IsSpace(r)
+}
+
+func (w *CSVWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	if w.dialect.BOM {
+		_, err := w.w.WriteString("\xEF\xBB\xBF")
+		return err
+	}
+	return nil
 }
 
+// Write writes a single record, writes are buffered,
+// so Flush must eventually be called to ensure that the record is written to the underlying io.Writer.
+func (w *CSVWriter) Write(record []string) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	for n, field := range record {
+		if n > 0 {
+			if _, err := w.w.WriteRune(w.dialect.Comma); err != nil {
+				return err
+			}
+		}
+		if !w.fieldNeedsQuotes(field) {
+			if _, err := w.w.WriteString(field); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.w.WriteByte('"'); err != nil {
+			return err
+		}
+		if _, err := w.w.WriteString(strings.ReplaceAll(field, `"`, `""`)); err != nil {
+			return err
+		}
+		if err := w.w.WriteByte('"'); err != nil {
+			return err
+		}
+	}
+	lineEnd := "\n"
+	if w.dialect.UseCRLF {
+		lineEnd = "\r\n"
+	}
+	_, err := w.w.WriteString(lineEnd)
+	return err
+}
+
+// WriteAll writes multiple records using Write and then calls Flush,
+// returning any error from the Flush.
+func (w *CSVWriter) WriteAll(records [][]string) error {
+	for _, record := range records {
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	return w.w.Flush()
+}
+
+// WriteHeader writes fields as the headline
+func (w *CSVWriter) WriteHeader(fields []string) error {
+	return w.WriteAll([][]string{fields})
+}
+
+// Flush writes buffered records to the underlying io.Writer. With an output encoding,
+// records pass through the encoder immediately, as it only holds a rune until it is complete,
+// so the flushed output is complete records before Close.
 func (w *CSVWriter) Flush() {
-	w.Writer.Flush()
+	w.w.Flush()
+}
+
+// Close flushes records and the encoder of output encoding
+func (w *CSVWriter) Close() error {
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	if w.closer != nil {
+		return w.closer.Close()
+	}
+	return nil
 }
 
 // NewCSVWriter generate CSVWriter
 func NewCSVWriter(w io.Writer) *CSVWriter {
-	return &CSVWriter{
-		Writer: csv.NewWriter(w),
+	writer, _ := NewCSVDialectWriter(w, CSVDialect{})
+	return writer
+}
+
+// NewCSVDialectWriter generate CSVWriter of dialect
+func NewCSVDialectWriter(w io.Writer, dialect CSVDialect) (*CSVWriter, error) {
+	if dialect.Comma == 0 {
+		dialect.Comma = ','
+	}
+	if dialect.Comma == '"' || dialect.Comma == '\r' || dialect.Comma == '\n' || !utf8.ValidRune(dialect.Comma) {
+		return nil, fmt.Errorf("invalid csv delimiter: %q", dialect.Comma)
+	}
+
+	writer := &CSVWriter{dialect: dialect}
+	if len(dialect.Encoding) > 0 {
+		enc, ok := CSVEncodings[strings.ToLower(dialect.Encoding)]
+		if !ok {
+			return nil, fmt.Errorf("unknown csv encoding: %s", dialect.Encoding)
+		}
+		if dialect.BOM {
+			return nil, errors.New("utf-8 BOM cannot be used with encoding " + dialect.Encoding)
+		}
+		ew := transform.NewWriter(w, encoding.ReplaceUnsupported(enc.NewEncoder()))
+		writer.closer = ew
+		w = ew
 	}
+	writer.w = bufio.NewWriter(w)
+	return writer, nil
 }
@@ -0,0 +1,89 @@
+package outformats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var b bytes.Buffer
+	w := NewCSVWriter(&b)
+	assert.Nil(t, w.WriteHeader([]string{"ip", "title"}))
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", `a,"b"`}, {"1.1.1.2", " c"}, {"1.1.1.3", ""}}))
+	assert.Nil(t, w.Close())
+	assert.Equal(t, "ip,title\n1.1.1.1,\"a,\"\"b\"\"\"\n1.1.1.2,\" c\"\n1.1.1.3,\n", b.String())
+
+	// tsv
+	b.Reset()
+	w, err := NewCSVDialectWriter(&b, CSVDialect{Comma: '\t', UseCRLF: true})
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "a,b"}, {"1.1.1.2", "a\tb"}}))
+	assert.Equal(t, "1.1.1.1\ta,b\r\n1.1.1.2\t\"a\tb\"\r\n", b.String())
+
+	// quote styles
+	b.Reset()
+	w, err = NewCSVDialectWriter(&b, CSVDialect{Quote: CSVQuoteAll})
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80"}}))
+	assert.Equal(t, "\"1.1.1.1\",\"80\"\n", b.String())
+
+	b.Reset()
+	w, err = NewCSVDialectWriter(&b, CSVDialect{Quote: CSVQuoteNone})
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "a,b"}}))
+	assert.Equal(t, "1.1.1.1,a,b\n", b.String())
+
+	_, err = ParseCSVQuote("some")
+	assert.Error(t, err)
+	_, err = NewCSVDialectWriter(&b, CSVDialect{Comma: '"'})
+	assert.Error(t, err)
+}
+
+func TestCSVWriter_Encoding(t *testing.T) {
+	// bom is written once
+	var b bytes.Buffer
+	w, err := NewCSVDialectWriter(&b, CSVDialect{BOM: true})
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteHeader([]string{"title"}))
+	assert.Nil(t, w.WriteAll([][]string{{"后台"}}))
+	assert.Equal(t, "\xEF\xBB\xBFtitle\n后台\n", b.String())
+
+	b.Reset()
+	w, err = NewCSVDialectWriter(&b, CSVDialect{Encoding: "GBK"})
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "后台"}}))
+	assert.Nil(t, w.Close())
+	d, err := simplifiedchinese.GBK.NewDecoder().Bytes(b.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, "1.1.1.1,后台\n", string(d))
+	assert.NotEqual(t, "1.1.1.1,后台\n", b.String())
+
+	// encoded records are streamed by Flush, even if the buffer is full in the middle of a rune
+	for _, encoding := range []string{"gbk", "gb18030"} {
+		b.Reset()
+		w, err = NewCSVDialectWriter(&b, CSVDialect{Encoding: encoding})
+		assert.Nil(t, err)
+		long := strings.Repeat("后台", 3000)
+		assert.Nil(t, w.Write([]string{"1.1.1.1", long}))
+		w.Flush()
+		d, err = simplifiedchinese.GB18030.NewDecoder().Bytes(b.Bytes())
+		assert.Nil(t, err)
+		assert.Equal(t, "1.1.1.1,"+long+"\n", string(d), encoding)
+		assert.Nil(t, w.WriteAll([][]string{{"1.1.1.2", "后台"}}))
+		n := b.Len()
+		d, err = simplifiedchinese.GB18030.NewDecoder().Bytes(b.Bytes())
+		assert.Nil(t, err)
+		assert.True(t, strings.HasSuffix(string(d), "\n1.1.1.2,后台\n"), encoding)
+		assert.Nil(t, w.Close())
+		assert.Equal(t, n, b.Len(), encoding)
+	}
+
+	_, err = NewCSVDialectWriter(&b, CSVDialect{Encoding: "big5"})
+	assert.Error(t, err)
+	_, err = NewCSVDialectWriter(&b, CSVDialect{Encoding: "gbk", BOM: true})
+	assert.Error(t, err)
+}
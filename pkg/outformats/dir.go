@@ -0,0 +1,142 @@
+package outformats
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// SanitizeName file name of query, chars other than letters, digits, _ and - are replaced by _
+func SanitizeName(query string, maxLen int) string {
+	name := strings.Trim(unsafeNameChars.ReplaceAllString(query, "_"), "_")
+	if len(name) > maxLen {
+		name = strings.TrimRight(name[:maxLen], "_")
+	}
+	return name
+}
+
+// DirWriter writes records of each query to a new file in dir,
+// the query is started by SetMeta, file is named by sanitized query or index of query with ext.
+// Records written before any query are written to file named by index.
+type DirWriter struct {
+	dir     string
+	ext     string
+	byIndex bool
+	newFile func(path string) (OutWriter, error)
+
+	writer OutWriter
+	header []string // written to each file
+	files  []string // files of closed writers
+	index  int
+	names  map[string]bool
+	err    error // error of SetMeta, returned by next WriteAll
+}
+
+func (w *DirWriter) name(query string) string {
+	name := fmt.Sprintf("%04d", w.index)
+	if !w.byIndex {
+		if s := SanitizeName(query, 100); s != "" {
+			name = s
+		}
+	}
+	if w.names[name] {
+		// same sanitized query
+		name = fmt.Sprintf("%s_%d", name, w.index)
+	}
+	w.names[name] = true
+	return name
+}
+
+func (w *DirWriter) closeFile() error {
+	if w.writer == nil {
+		return nil
+	}
+	err := Close(w.writer)
+	w.files = append(w.files, Files(w.writer)...)
+	w.writer = nil
+	return err
+}
+
+func (w *DirWriter) next(query string) error {
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+	w.index++
+	if err := os.MkdirAll(w.dir, 0755); err != nil {
+		return err
+	}
+	writer, err := w.newFile(filepath.Join(w.dir, w.name(query)+w.ext))
+	if err != nil {
+		return err
+	}
+	w.writer = writer
+	if w.header != nil {
+		return writer.WriteHeader(w.header)
+	}
+	return nil
+}
+
+// WriteHeader writes header to each file
+func (w *DirWriter) WriteHeader(fields []string) error {
+	w.header = fields
+	if w.writer != nil {
+		return w.writer.WriteHeader(fields)
+	}
+	return nil
+}
+
+// SetMeta closes file of previous query, and opens file of query
+func (w *DirWriter) SetMeta(query string, size int) {
+	if w.err = w.next(query); w.err != nil {
+		return
+	}
+	SetMeta(w.writer, query, size)
+}
+
+// WriteAll writes records to file of current query
+func (w *DirWriter) WriteAll(records [][]string) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.writer == nil {
+		if err := w.next(""); err != nil {
+			return err
+		}
+	}
+	return w.writer.WriteAll(records)
+}
+
+func (w *DirWriter) Flush() {
+	if w.writer != nil {
+		w.writer.Flush()
+	}
+}
+
+// Files paths of files of all queries
+func (w *DirWriter) Files() []string {
+	if w.writer != nil {
+		return append(w.files[:len(w.files):len(w.files)], Files(w.writer)...)
+	}
+	return w.files
+}
+
+// Close file of current query
+func (w *DirWriter) Close() error {
+	return w.closeFile()
+}
+
+// NewDirWriter generate writer of dir, files named by index of query if byIndex, else by sanitized query,
+// ext is the extension of files like .csv.gz, newFile generates writer of each file
+func NewDirWriter(dir string, ext string, byIndex bool, newFile func(path string) (OutWriter, error)) *DirWriter {
+	return &DirWriter{
+		dir:     dir,
+		ext:     ext,
+		byIndex: byIndex,
+		newFile: newFile,
+		names:   make(map[string]bool),
+	}
+}
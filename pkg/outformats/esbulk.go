@@ -0,0 +1,242 @@
+package outformats
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ESBulkWriter writes records as elasticsearch/opensearch _bulk NDJSON, like:
+// {"index":{"_index":"fofa","_id":"1.1.1.1:80"}}
+// {"ip":"1.1.1.1","port":"80"}
+type ESBulkWriter struct {
+	noHeader
+
+	fields    []string
+	w         *bufio.Writer
+	index     string
+	idIndexes []int // index of id fields, empty means id generated by server
+	hashID    bool  // id is sha1 of document if there are no id fields
+	typed     bool
+}
+
+// Write writes the action and document of a single record.
+// Writes are buffered, so Flush must eventually be called to ensure
+// that the record is written to the underlying io.Writer.
+func (w *ESBulkWriter) Write(records []string) error {
+	if len(records) != len(w.fields) {
+		return errors.New("records length is not equal to fields")
+	}
+
+	doc, err := json.Marshal(recordValues(w.fields, records, w.typed))
+	if err != nil {
+		return err
+	}
+	meta := map[string]string{"_index": w.index}
+	if len(w.idIndexes) > 0 {
+		var ids []string
+		for _, i := range w.idIndexes {
+			ids = append(ids, records[i])
+		}
+		meta["_id"] = strings.Join(ids, ":")
+	} else if w.hashID {
+		sum := sha1.Sum(doc)
+		meta["_id"] = hex.EncodeToString(sum[:])
+	}
+	action, err := json.Marshal(map[string]interface{}{"index": meta})
+	if err != nil {
+		return err
+	}
+
+	for _, d := range [][]byte{action, doc} {
+		if _, err = w.w.Write(d); err != nil {
+			return err
+		}
+		if err = w.w.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteAll writes multiple records to w using Write and then calls Flush,
+// returning any error from the Flush.
+func (w *ESBulkWriter) WriteAll(records [][]string) error {
+	for _, record := range records {
+		err := w.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	return w.w.Flush()
+}
+
+func (w *ESBulkWriter) Flush() {
+	w.w.Flush()
+}
+
+// NewESBulkWriter generate _bulk writer
+// fields are key field, index is the index name,
+// document id is joined by values of idFields with :, empty idFields means id generated by server
+func NewESBulkWriter(w io.Writer, fields []string, index string, idFields []string, typed bool) (*ESBulkWriter, error) {
+	if index == "" {
+		return nil, errors.New("index name cannot be empty")
+	}
+	idIndexes := recordIndexes(fields, idFields...)
+	for i, idx := range idIndexes {
+		if idx == -1 {
+			return nil, fmt.Errorf("id field %s not in fields", idFields[i])
+		}
+	}
+	return &ESBulkWriter{
+		w:         bufio.NewWriter(w),
+		fields:    fields,
+		index:     index,
+		idIndexes: idIndexes,
+		typed:     typed,
+	}, nil
+}
+
+// ESSink sends records to the _bulk api of elasticsearch/opensearch directly,
+// each WriteAll is sent as bulk requests of at most BatchSize records,
+// requests are retried on network error, 429 and 5xx, and only items failed by 429 are sent again.
+// Without id fields, the id of document is its sha1 if Retry is set, so that retried requests do not duplicate documents.
+type ESSink struct {
+	noHeader
+
+	BulkURL   string        // like http://127.0.0.1:9200/_bulk
+	Retry     int           // retry count
+	RetryWait time.Duration // wait before retry, increased by attempts
+	BatchSize int           // max records of each request
+
+	bulk       *ESBulkWriter
+	buf        bytes.Buffer
+	user       *url.Userinfo
+	httpClient *http.Client
+}
+
+type esBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// post sends records as a bulk request, failed are the records to send again if retryable
+func (s *ESSink) post(records [][]string) (failed [][]string, retryable bool, err error) {
+	s.buf.Reset()
+	s.bulk.hashID = s.Retry > 0
+	if err = s.bulk.WriteAll(records); err != nil {
+		return nil, false, err
+	}
+
+	req, err := http.NewRequest("POST", s.BulkURL, bytes.NewReader(s.buf.Bytes()))
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.user != nil {
+		password, _ := s.user.Password()
+		req.SetBasicAuth(s.user.Username(), password)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return records, true, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return records, true, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return records, true, fmt.Errorf("bulk request failed: %s", resp.Status)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, false, fmt.Errorf("bulk request failed: %s, %s", resp.Status, data)
+	}
+
+	var br esBulkResponse
+	if err = json.Unmarshal(data, &br); err != nil {
+		return nil, false, fmt.Errorf("invalid bulk response: %w", err)
+	}
+	if !br.Errors {
+		return nil, false, nil
+	}
+	if len(br.Items) != len(records) {
+		return nil, false, fmt.Errorf("invalid bulk response: %d items of %d records", len(br.Items), len(records))
+	}
+	// items are in order of records, only items rejected by 429 are sent again
+	for i, item := range br.Items {
+		for _, r := range item {
+			if r.Status < 300 {
+				continue
+			}
+			err = fmt.Errorf("bulk item failed: %d %s %s", r.Status, r.Error.Type, r.Error.Reason)
+			if r.Status != http.StatusTooManyRequests {
+				return nil, false, err
+			}
+			failed = append(failed, records[i])
+		}
+	}
+	return failed, len(failed) > 0, err
+}
+
+func (s *ESSink) send(records [][]string) error {
+	return retry(s.Retry, s.RetryWait, func() (retryable bool, err error) {
+		records, retryable, err = s.post(records)
+		return retryable, err
+	})
+}
+
+// WriteAll sends records as bulk requests
+func (s *ESSink) WriteAll(records [][]string) error {
+	return eachBatch(records, s.BatchSize, s.send)
+}
+
+func (s *ESSink) Flush() {
+}
+
+// NewESSink generate elasticsearch sink from url like es://127.0.0.1:9200/index,
+// es+https:// uses https, user and password of url are used as basic auth
+func NewESSink(sinkURL string, fields []string, idFields []string, typed bool) (*ESSink, error) {
+	u, err := url.Parse(sinkURL)
+	if err != nil {
+		return nil, err
+	}
+	scheme := "http"
+	switch u.Scheme {
+	case "es":
+	case "es+https":
+		scheme = "https"
+	default:
+		return nil, fmt.Errorf("invalid elasticsearch sink: %s", sinkURL)
+	}
+
+	s := &ESSink{
+		BulkURL:    (&url.URL{Scheme: scheme, Host: u.Host, Path: "/_bulk"}).String(),
+		Retry:      3,
+		RetryWait:  time.Second,
+		BatchSize:  1000,
+		user:       u.User,
+		httpClient: &http.Client{Timeout: time.Minute},
+	}
+	s.bulk, err = NewESBulkWriter(&s.buf, fields, strings.Trim(u.Path, "/"), idFields, typed)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
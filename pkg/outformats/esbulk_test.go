@@ -0,0 +1,130 @@
+package outformats
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestESBulkWriter(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := NewESBulkWriter(&buf, []string{"ip", "port"}, "", nil, false)
+	assert.Error(t, err)
+	_, err = NewESBulkWriter(&buf, []string{"ip", "port"}, "fofa", []string{"host"}, false)
+	assert.Error(t, err)
+
+	w, err := NewESBulkWriter(&buf, []string{"ip", "port"}, "fofa", []string{"ip", "port"}, true)
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80"}}))
+	assert.Equal(t, `{"index":{"_id":"1.1.1.1:80","_index":"fofa"}}
+{"ip":"1.1.1.1","port":80}
+`, buf.String())
+
+	buf.Reset()
+	w, err = NewESBulkWriter(&buf, []string{"ip", "port"}, "fofa", nil, false)
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80"}}))
+	assert.Equal(t, `{"index":{"_index":"fofa"}}
+{"ip":"1.1.1.1","port":"80"}
+`, buf.String())
+}
+
+func TestESSink(t *testing.T) {
+	var requests int32
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		assert.Equal(t, "/_bulk", r.URL.Path)
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		user, password, _ := r.BasicAuth()
+		assert.Equal(t, "elastic", user)
+		assert.Equal(t, "pass", password)
+		if n == 1 {
+			// first request fails, retried
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		d, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(d))
+		if strings.Contains(string(d), "2.2.2.2") {
+			w.Write([]byte(`{"errors":true,"items":[{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}]}`))
+			return
+		}
+		w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	defer ts.Close()
+
+	_, err := NewESSink("http://127.0.0.1/fofa", []string{"ip"}, nil, false)
+	assert.Error(t, err)
+	_, err = NewESSink(strings.Replace(ts.URL, "http://", "es://", 1), []string{"ip"}, nil, false)
+	assert.Error(t, err)
+
+	s, err := NewESSink(strings.Replace(ts.URL, "http://", "es://elastic:pass@", 1)+"/fofa", []string{"ip", "port"}, []string{"ip", "port"}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, ts.URL+"/_bulk", s.BulkURL)
+	s.RetryWait = time.Millisecond
+	s.BatchSize = 2
+
+	assert.Nil(t, s.WriteAll([][]string{{"1.1.1.1", "80"}, {"1.1.1.1", "443"}, {"1.1.1.2", "80"}}))
+	assert.Equal(t, int32(3), requests)
+	assert.Equal(t, 2, len(bodies))
+	assert.Equal(t, 4, strings.Count(bodies[0], "\n"))
+	assert.Contains(t, bodies[1], `"_id":"1.1.1.2:80"`)
+
+	// item errors are not retried
+	err = s.WriteAll([][]string{{"2.2.2.2", "80"}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "mapper_parsing_exception")
+	assert.Equal(t, int32(4), requests)
+
+	// all retries failed
+	ts.Close()
+	s.Retry = 1
+	assert.Error(t, s.WriteAll([][]string{{"1.1.1.1", "80"}}))
+}
+
+func TestESSink_PartialRetry(t *testing.T) {
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(d))
+		if len(bodies) == 1 {
+			// second item is rejected, the others are indexed
+			w.Write([]byte(`{"errors":true,"items":[{"index":{"status":201}},{"index":{"status":429,"error":{"type":"es_rejected_execution_exception"}}},{"index":{"status":201}}]}`))
+			return
+		}
+		w.Write([]byte(`{"errors":false,"items":[{"index":{"status":201}}]}`))
+	}))
+	defer ts.Close()
+
+	s, err := NewESSink(strings.Replace(ts.URL, "http://", "es://", 1)+"/fofa", []string{"ip", "port"}, nil, false)
+	assert.Nil(t, err)
+	s.RetryWait = time.Millisecond
+	assert.Nil(t, s.WriteAll([][]string{{"1.1.1.1", "80"}, {"1.1.1.2", "80"}, {"1.1.1.3", "80"}}))
+
+	// only the rejected item is sent again, with id of its document
+	assert.Equal(t, 2, len(bodies))
+	assert.Equal(t, 6, strings.Count(bodies[0], "\n"))
+	sum := sha1.Sum([]byte(`{"ip":"1.1.1.2","port":"80"}`))
+	assert.Equal(t, `{"index":{"_id":"`+hex.EncodeToString(sum[:])+`","_index":"fofa"}}
+{"ip":"1.1.1.2","port":"80"}
+`, bodies[1])
+	assert.Contains(t, bodies[0], bodies[1])
+
+	// ids are generated by server without retries
+	s.Retry = 0
+	bodies = []string{"", ""}
+	assert.Nil(t, s.WriteAll([][]string{{"1.1.1.1", "80"}}))
+	assert.Equal(t, `{"index":{"_index":"fofa"}}
+{"ip":"1.1.1.1","port":"80"}
+`, bodies[2])
+}
@@ -0,0 +1,182 @@
+package outformats
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+)
+
+type geoJSONGeometry struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   *geoJSONGeometry       `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GeoJSONWriter writes records as GeoJSON FeatureCollection,
+// each record is a point feature of longitude and latitude, other fields are properties.
+// If cluster is not less than 0, records are clustered by coordinates rounded to cluster decimals,
+// each cluster is a feature with count, country and city properties, written when Close is called.
+type GeoJSONWriter struct {
+	noHeader
+
+	fields  []string
+	w       *bufio.Writer
+	typed   bool
+	cluster int
+	latIdx  int
+	lonIdx  int
+	started bool
+
+	clusters    []*geoJSONFeature
+	clusterKeys map[string]*geoJSONFeature
+}
+
+func (w *GeoJSONWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	_, err := w.w.WriteString(`{"type":"FeatureCollection","features":[` + "\n")
+	return err
+}
+
+func (w *GeoJSONWriter) writeFeature(f *geoJSONFeature, first bool) error {
+	d, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if !first {
+		if _, err = w.w.WriteString(",\n"); err != nil {
+			return err
+		}
+	}
+	_, err = w.w.Write(d)
+	return err
+}
+
+// coordinates of record, ok is false if latitude or longitude is invalid
+func (w *GeoJSONWriter) coordinates(records []string) (lon float64, lat float64, ok bool) {
+	lat, err := strconv.ParseFloat(records[w.latIdx], 64)
+	if err != nil {
+		return
+	}
+	lon, err = strconv.ParseFloat(records[w.lonIdx], 64)
+	if err != nil {
+		return
+	}
+	return lon, lat, true
+}
+
+// Write writes a single record as a point feature, geometry is null if coordinates are invalid.
+// In cluster mode the record is counted to its cluster, records without coordinates are ignored.
+func (w *GeoJSONWriter) Write(records []string) error {
+	if len(records) != len(w.fields) {
+		return errors.New("records length is not equal to fields")
+	}
+	lon, lat, ok := w.coordinates(records)
+
+	if w.cluster >= 0 {
+		if !ok {
+			return nil
+		}
+		p := math.Pow10(w.cluster)
+		lon, lat = math.Round(lon*p)/p, math.Round(lat*p)/p
+		key := strconv.FormatFloat(lon, 'f', -1, 64) + "," + strconv.FormatFloat(lat, 'f', -1, 64)
+		f, exists := w.clusterKeys[key]
+		if !exists {
+			f = &geoJSONFeature{
+				Type:       "Feature",
+				Geometry:   &geoJSONGeometry{Type: "Point", Coordinates: [2]float64{lon, lat}},
+				Properties: map[string]interface{}{"count": 0},
+			}
+			for i, field := range w.fields {
+				if field == "country" || field == "city" {
+					f.Properties[field] = records[i]
+				}
+			}
+			w.clusterKeys[key] = f
+			w.clusters = append(w.clusters, f)
+		}
+		f.Properties["count"] = f.Properties["count"].(int) + 1
+		return nil
+	}
+
+	f := &geoJSONFeature{
+		Type:       "Feature",
+		Properties: make(map[string]interface{}),
+	}
+	if ok {
+		f.Geometry = &geoJSONGeometry{Type: "Point", Coordinates: [2]float64{lon, lat}}
+	}
+	for k, v := range recordValues(w.fields, records, w.typed) {
+		if k != "latitude" && k != "longitude" {
+			f.Properties[k] = v
+		}
+	}
+
+	first := !w.started
+	if err := w.start(); err != nil {
+		return err
+	}
+	return w.writeFeature(f, first)
+}
+
+// WriteAll writes multiple records using Write and then calls Flush,
+// returning any error from the Flush.
+func (w *GeoJSONWriter) WriteAll(records [][]string) error {
+	for _, record := range records {
+		err := w.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	return w.w.Flush()
+}
+
+func (w *GeoJSONWriter) Flush() {
+	w.w.Flush()
+}
+
+// Close writes clusters and the end of FeatureCollection
+func (w *GeoJSONWriter) Close() error {
+	first := !w.started
+	if err := w.start(); err != nil {
+		return err
+	}
+	for i, f := range w.clusters {
+		if err := w.writeFeature(f, first && i == 0); err != nil {
+			return err
+		}
+	}
+	if _, err := w.w.WriteString("\n]}\n"); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// NewGeoJSONWriter generate GeoJSON writer
+// fields must contain latitude and longitude, properties are typed by FieldTypes if typed,
+// cluster is the decimals of coordinates to cluster records, -1 means no cluster
+func NewGeoJSONWriter(w io.Writer, fields []string, typed bool, cluster int) (*GeoJSONWriter, error) {
+	indexes := recordIndexes(fields, "latitude", "longitude")
+	if indexes[0] == -1 || indexes[1] == -1 {
+		return nil, errors.New("geojson format needs latitude and longitude fields")
+	}
+	return &GeoJSONWriter{
+		w:           bufio.NewWriter(w),
+		fields:      fields,
+		typed:       typed,
+		cluster:     cluster,
+		latIdx:      indexes[0],
+		lonIdx:      indexes[1],
+		clusterKeys: make(map[string]*geoJSONFeature),
+	}, nil
+}
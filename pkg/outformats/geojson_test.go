@@ -0,0 +1,68 @@
+package outformats
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type geoJSONCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+func TestGeoJSONWriter(t *testing.T) {
+	_, err := NewGeoJSONWriter(&bytes.Buffer{}, []string{"ip", "latitude"}, false, -1)
+	assert.Error(t, err)
+
+	fields := []string{"ip", "port", "latitude", "longitude", "country", "city"}
+	records := [][]string{
+		{"1.1.1.1", "80", "39.9042", "116.4074", "CN", "Beijing"},
+		{"1.1.1.2", "80", "", "", "CN", ""},
+		{"1.1.1.3", "443", "39.9", "116.41", "CN", "Beijing"},
+		{"1.1.1.4", "443", "40.7128", "-74.006", "US", "New York"},
+	}
+
+	// one feature of each record, geometry is null without coordinates
+	var b bytes.Buffer
+	w, err := NewGeoJSONWriter(&b, fields, true, -1)
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteAll(records))
+	assert.Nil(t, w.Close())
+	var fc geoJSONCollection
+	assert.Nil(t, json.Unmarshal(b.Bytes(), &fc))
+	assert.Equal(t, "FeatureCollection", fc.Type)
+	assert.Equal(t, 4, len(fc.Features))
+	assert.Equal(t, [2]float64{116.4074, 39.9042}, fc.Features[0].Geometry.Coordinates)
+	assert.Equal(t, map[string]interface{}{"ip": "1.1.1.1", "port": float64(80), "country": "CN", "city": "Beijing"},
+		fc.Features[0].Properties)
+	assert.Nil(t, fc.Features[1].Geometry)
+
+	// clusters skip records without coordinates
+	b.Reset()
+	w, err = NewGeoJSONWriter(&b, fields, false, 1)
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteAll(records))
+	// clusters are written on Close
+	assert.Equal(t, 0, b.Len())
+	assert.Nil(t, w.Close())
+	fc = geoJSONCollection{}
+	assert.Nil(t, json.Unmarshal(b.Bytes(), &fc))
+	assert.Equal(t, 2, len(fc.Features))
+	assert.Equal(t, [2]float64{116.4, 39.9}, fc.Features[0].Geometry.Coordinates)
+	assert.Equal(t, map[string]interface{}{"count": float64(2), "country": "CN", "city": "Beijing"}, fc.Features[0].Properties)
+	assert.Equal(t, [2]float64{-74, 40.7}, fc.Features[1].Geometry.Coordinates)
+	assert.Equal(t, float64(1), fc.Features[1].Properties["count"])
+
+	// empty collection
+	b.Reset()
+	w, err = NewGeoJSONWriter(&b, fields, false, 0)
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteAll([][]string{records[1]}))
+	assert.Nil(t, w.Close())
+	fc = geoJSONCollection{}
+	assert.Nil(t, json.Unmarshal(b.Bytes(), &fc))
+	assert.Empty(t, fc.Features)
+}
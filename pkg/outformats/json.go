@@ -9,8 +9,24 @@ import (
 
 // JSONWriter JSON format writer
 type JSONWriter struct {
+	noHeader
+
 	fields []string
 	w      *bufio.Writer
+	typed  bool // convert values by FieldTypes
+}
+
+// recordValues map record to fields, values are converted by FieldTypes if typed
+func recordValues(fields []string, records []string, typed bool) map[string]interface{} {
+	m := make(map[string]interface{})
+	for i := range fields {
+		if typed {
+			m[fields[i]] = TypedValue(fields[i], records[i])
+		} else {
+			m[fields[i]] = records[i]
+		}
+	}
+	return m
 }
 
 // Write writes a single JSON record to w one line.
@@ -22,11 +38,7 @@ func (w *JSONWriter) Write(records []string) error {
 		return errors.New("records length is not equal to fields")
 	}
 
-	m := make(map[string]string)
-	for i := range w.fields {
-		m[w.fields[i]] = records[i]
-	}
-	d, err := json.Marshal(m)
+	d, err := json.Marshal(recordValues(w.fields, records, w.typed))
 	if err != nil {
 		return err
 	}
@@ -65,3 +77,13 @@ func NewJSONWriter(w io.Writer, fields []string) *JSONWriter {
 		fields: fields,
 	}
 }
+
+// NewTypedJSONWriter generate json writer which values are typed by FieldTypes,
+// like port as number, certs_domains as array, lastupdatetime as RFC3339
+func NewTypedJSONWriter(w io.Writer, fields []string) *JSONWriter {
+	return &JSONWriter{
+		w:      bufio.NewWriter(w),
+		fields: fields,
+		typed:  true,
+	}
+}
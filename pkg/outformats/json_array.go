@@ -0,0 +1,126 @@
+package outformats
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+// JSONArrayWriter writes a single json document with meta data envelope:
+// {"results":[...],"query":"","fields":[],"size":0,"fetched":0}
+type JSONArrayWriter struct {
+	noHeader
+
+	fields  []string
+	w       *bufio.Writer
+	typed   bool     // convert values by FieldTypes
+	queries []string // queries set by SetMeta
+	size    int      // total size reported by server
+	fetched int      // records written
+	started bool     // the head of document is written
+}
+
+// Write writes a single JSON record to w as an element of results.
+// Writes are buffered, so Close must eventually be called to ensure
+// that the document is ended and written to the underlying io.Writer.
+func (w *JSONArrayWriter) Write(records []string) error {
+	if len(records) != len(w.fields) {
+		return errors.New("records length is not equal to fields")
+	}
+
+	d, err := json.Marshal(recordValues(w.fields, records, w.typed))
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n"
+	if !w.started {
+		sep = `{"results":[` + "\n"
+		w.started = true
+	}
+	if _, err = w.w.WriteString(sep); err != nil {
+		return err
+	}
+	if _, err = w.w.Write(d); err != nil {
+		return err
+	}
+	w.fetched++
+
+	return nil
+}
+
+// WriteAll writes multiple json records to w using Write and then calls Flush,
+// returning any error from the Flush.
+func (w *JSONArrayWriter) WriteAll(records [][]string) error {
+	for _, record := range records {
+		err := w.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	return w.w.Flush()
+}
+
+func (w *JSONArrayWriter) Flush() {
+	w.w.Flush()
+}
+
+// SetMeta add query and its total size to meta data,
+// multiple queries are joined with ||
+func (w *JSONArrayWriter) SetMeta(query string, size int) {
+	w.queries = append(w.queries, query)
+	w.size += size
+}
+
+// Close writes the meta data to end the document
+func (w *JSONArrayWriter) Close() error {
+	query := ""
+	if len(w.queries) == 1 {
+		query = w.queries[0]
+	} else if len(w.queries) > 1 {
+		query = "(" + strings.Join(w.queries, ") || (") + ")"
+	}
+	d, err := json.Marshal(struct {
+		Query   string   `json:"query"`
+		Fields  []string `json:"fields"`
+		Size    int      `json:"size"`
+		Fetched int      `json:"fetched"`
+	}{
+		Query:   query,
+		Fields:  w.fields,
+		Size:    w.size,
+		Fetched: w.fetched,
+	})
+	if err != nil {
+		return err
+	}
+
+	head := "\n],"
+	if !w.started {
+		head = `{"results":[],`
+		w.started = true
+	}
+	if _, err = w.w.WriteString(head); err != nil {
+		return err
+	}
+	// merge meta object into the envelope after results
+	if _, err = w.w.Write(d[1:]); err != nil {
+		return err
+	}
+	if _, err = w.w.WriteString("\n"); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// NewJSONArrayWriter generate json array writer
+// fields are key field, values are converted by FieldTypes if typed
+func NewJSONArrayWriter(w io.Writer, fields []string, typed bool) *JSONArrayWriter {
+	return &JSONArrayWriter{
+		w:      bufio.NewWriter(w),
+		fields: fields,
+		typed:  typed,
+	}
+}
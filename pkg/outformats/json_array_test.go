@@ -0,0 +1,51 @@
+package outformats
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONArrayWriter(t *testing.T) {
+	var v struct {
+		Results []map[string]interface{} `json:"results"`
+		Query   string                   `json:"query"`
+		Fields  []string                 `json:"fields"`
+		Size    int                      `json:"size"`
+		Fetched int                      `json:"fetched"`
+	}
+
+	// no rows
+	var b bytes.Buffer
+	w := NewJSONArrayWriter(&b, []string{"ip", "port"}, false)
+	w.SetMeta("port=80", 0)
+	assert.Nil(t, w.Close())
+	assert.JSONEq(t, `{"results":[],"query":"port=80","fields":["ip","port"],"size":0,"fetched":0}`, b.String())
+
+	// one row
+	b.Reset()
+	w = NewJSONArrayWriter(&b, []string{"ip", "port"}, true)
+	w.SetMeta("port=80", 10)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80"}}))
+	assert.Nil(t, w.Close())
+	assert.JSONEq(t, `{"results":[{"ip":"1.1.1.1","port":80}],"query":"port=80","fields":["ip","port"],"size":10,"fetched":1}`, b.String())
+
+	// multiple rows and queries
+	b.Reset()
+	w = NewJSONArrayWriter(&b, []string{"ip", "port"}, false)
+	w.SetMeta("port=80", 10)
+	w.SetMeta("port=443", 5)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80"}, {"1.1.1.2", "443"}}))
+	assert.Nil(t, w.Write([]string{"1.1.1.3", "443"}))
+	assert.Nil(t, w.Close())
+	assert.Nil(t, json.Unmarshal(b.Bytes(), &v))
+	assert.Equal(t, 3, len(v.Results))
+	assert.Equal(t, "1.1.1.3", v.Results[2]["ip"])
+	assert.Equal(t, "(port=80) || (port=443)", v.Query)
+	assert.Equal(t, 15, v.Size)
+	assert.Equal(t, 3, v.Fetched)
+
+	assert.Error(t, w.Write([]string{"1.1.1.1"}))
+}
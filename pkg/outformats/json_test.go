@@ -0,0 +1,34 @@
+package outformats
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONWriter(t *testing.T) {
+	fields := []string{"ip", "port", "certs_domains", "isActive", "latitude", "lastupdatetime", "asn"}
+	records := [][]string{{"1.1.1.1", "80", "a.com, b.com", "true", "1.5", "2023-01-02 03:04:05", ""}}
+
+	var b bytes.Buffer
+	w := NewJSONWriter(&b, fields)
+	assert.Nil(t, w.WriteAll(records))
+	assert.JSONEq(t, `{"ip":"1.1.1.1","port":"80","certs_domains":"a.com, b.com","isActive":"true",
+		"latitude":"1.5","lastupdatetime":"2023-01-02 03:04:05","asn":""}`, b.String())
+
+	// values are typed by FieldTypes
+	b.Reset()
+	w = NewTypedJSONWriter(&b, fields)
+	assert.Nil(t, w.WriteAll(records))
+	assert.JSONEq(t, `{"ip":"1.1.1.1","port":80,"certs_domains":["a.com","b.com"],"isActive":true,
+		"latitude":1.5,"lastupdatetime":"2023-01-02T03:04:05+08:00","asn":null}`, b.String())
+
+	// keep string if failed to convert
+	b.Reset()
+	w = NewTypedJSONWriter(&b, []string{"port"})
+	assert.Nil(t, w.WriteAll([][]string{{"abc"}, {"443"}}))
+	assert.Equal(t, "{\"port\":\"abc\"}\n{\"port\":443}\n", b.String())
+
+	assert.Error(t, w.Write([]string{"1", "2"}))
+}
@@ -0,0 +1,98 @@
+package outformats
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// MasscanWriter writes records as masscan list format (-oL), like:
+// #masscan
+// open tcp 80 1.1.1.1 1700000000
+// # end
+type MasscanWriter struct {
+	noHeader
+
+	w       *bufio.Writer
+	indexes []int // index of ip, port, lastupdatetime, base_protocol
+	now     int64
+	started bool
+}
+
+func (w *MasscanWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	_, err := w.w.WriteString("#masscan\n")
+	return err
+}
+
+// Write writes a single record as an open port line,
+// timestamp is lastupdatetime if exists, otherwise now.
+func (w *MasscanWriter) Write(records []string) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	ip, port := records[w.indexes[0]], records[w.indexes[1]]
+	if ip == "" || port == "" {
+		return nil
+	}
+
+	ts := w.now
+	if w.indexes[2] != -1 {
+		if t, err := ParseTime(records[w.indexes[2]]); err == nil {
+			ts = t.Unix()
+		}
+	}
+	transport := "tcp"
+	if w.indexes[3] != -1 && records[w.indexes[3]] == "udp" {
+		transport = "udp"
+	}
+
+	_, err := fmt.Fprintf(w.w, "open %s %s %s %d\n", transport, port, ip, ts)
+	return err
+}
+
+// WriteAll writes multiple records using Write and then calls Flush,
+// returning any error from the Flush.
+func (w *MasscanWriter) WriteAll(records [][]string) error {
+	for _, record := range records {
+		err := w.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	return w.w.Flush()
+}
+
+func (w *MasscanWriter) Flush() {
+	w.w.Flush()
+}
+
+// Close writes the end of list
+func (w *MasscanWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if _, err := w.w.WriteString("# end\n"); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// NewMasscanWriter generate masscan list writer
+// fields must contain ip and port, lastupdatetime and base_protocol are optional
+func NewMasscanWriter(w io.Writer, fields []string) (*MasscanWriter, error) {
+	indexes := recordIndexes(fields, "ip", "port", "lastupdatetime", "base_protocol")
+	if indexes[0] == -1 || indexes[1] == -1 {
+		return nil, errors.New("masscan format needs ip and port fields")
+	}
+	return &MasscanWriter{
+		w:       bufio.NewWriter(w),
+		indexes: indexes,
+		now:     time.Now().Unix(),
+	}, nil
+}
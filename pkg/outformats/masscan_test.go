@@ -0,0 +1,38 @@
+package outformats
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMasscanWriter(t *testing.T) {
+	_, err := NewMasscanWriter(&bytes.Buffer{}, []string{"port"})
+	assert.Error(t, err)
+
+	var b bytes.Buffer
+	w, err := NewMasscanWriter(&b, []string{"ip", "port", "lastupdatetime", "base_protocol"})
+	assert.Nil(t, err)
+	w.now = 1700000000
+	assert.Nil(t, w.WriteAll([][]string{
+		{"1.1.1.1", "80", "2024-01-02 03:04:05", "tcp"},
+		{"1.1.1.2", "53", "", "udp"},
+		{"", "80", "", "tcp"},
+	}))
+	assert.Nil(t, w.Close())
+	ts, err := ParseTime("2024-01-02 03:04:05")
+	assert.Nil(t, err)
+	assert.Equal(t, "#masscan\n"+
+		"open tcp 80 1.1.1.1 "+strconv.FormatInt(ts.Unix(), 10)+"\n"+
+		"open udp 53 1.1.1.2 1700000000\n"+
+		"# end\n", b.String())
+
+	// empty list
+	b.Reset()
+	w, err = NewMasscanWriter(&b, []string{"ip", "port"})
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	assert.Equal(t, "#masscan\n# end\n", b.String())
+}
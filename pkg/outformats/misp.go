@@ -0,0 +1,168 @@
+package outformats
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+type mispAttribute struct {
+	UUID           string `json:"uuid"`
+	ObjectRelation string `json:"object_relation,omitempty"`
+	Type           string `json:"type"`
+	Category       string `json:"category"`
+	Value          string `json:"value"`
+	ToIDS          bool   `json:"to_ids"`
+	Comment        string `json:"comment,omitempty"`
+}
+
+// mispObject object of attributes, like x509 with subject and issuer
+type mispObject struct {
+	UUID         string          `json:"uuid"`
+	Name         string          `json:"name"`
+	MetaCategory string          `json:"meta-category"`
+	Attribute    []mispAttribute `json:"Attribute"`
+}
+
+type mispTag struct {
+	Name string `json:"name"`
+}
+
+// MISPWriter writes records and queries as a MISP event,
+// ip, ip|port, domain and url of records are attributes, certificates are x509 objects with subject and issuer,
+// queries are text attributes and the info of event. The event is written when Close is called.
+type MISPWriter struct {
+	noHeader
+
+	w          *bufio.Writer
+	indexes    []int // index of ip, host, domain, link, protocol, certs_subject_cn, certs_subject_org, certs_issuer_cn, certs_issuer_org, port
+	date       string
+	queries    []string
+	attributes []mispAttribute
+	objects    []mispObject
+	seen       map[string]bool // added attributes and objects
+}
+
+func (w *MISPWriter) add(typ string, category string, value string, comment string) {
+	key := typ + "\t" + value
+	if value == "" || w.seen[key] {
+		return
+	}
+	w.seen[key] = true
+	w.attributes = append(w.attributes, mispAttribute{
+		UUID:     newUUID(),
+		Type:     typ,
+		Category: category,
+		Value:    value,
+		Comment:  comment,
+	})
+}
+
+// addX509 adds x509 object of certificate subject and issuer
+func (w *MISPWriter) addX509(subject string, issuer string) {
+	key := "x509\t" + subject + "\t" + issuer
+	if (subject == "" && issuer == "") || w.seen[key] {
+		return
+	}
+	w.seen[key] = true
+	obj := mispObject{UUID: newUUID(), Name: "x509", MetaCategory: "network"}
+	for _, attr := range [][2]string{{"subject", subject}, {"issuer", issuer}} {
+		if attr[1] == "" {
+			continue
+		}
+		obj.Attribute = append(obj.Attribute, mispAttribute{
+			UUID:           newUUID(),
+			ObjectRelation: attr[0],
+			Type:           "text",
+			Category:       "Network activity",
+			Value:          attr[1],
+		})
+	}
+	w.objects = append(w.objects, obj)
+}
+
+// Write adds attributes of a single record
+func (w *MISPWriter) Write(records []string) error {
+	if w.indexes[0] != -1 {
+		if ip := net.ParseIP(records[w.indexes[0]]); ip != nil {
+			w.add("ip-dst", "Network activity", ip.String(), "")
+			if w.indexes[9] != -1 && records[w.indexes[9]] != "" {
+				w.add("ip-dst|port", "Network activity", ip.String()+"|"+records[w.indexes[9]], "")
+			}
+		}
+	}
+	if name := recordHost(records, w.indexes[1], w.indexes[2]); name != "" {
+		w.add("domain", "Network activity", name, "")
+	}
+	if u := recordURL(records, w.indexes[3], w.indexes[1], w.indexes[4]); u != "" {
+		w.add("url", "Network activity", u, "")
+	}
+	w.addX509(recordCert(records, w.indexes[5:9]))
+	return nil
+}
+
+// WriteAll adds multiple records using Write
+func (w *MISPWriter) WriteAll(records [][]string) error {
+	for _, record := range records {
+		err := w.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *MISPWriter) Flush() {
+	w.w.Flush()
+}
+
+// SetMeta adds the query as a text attribute of event
+func (w *MISPWriter) SetMeta(query string, size int) {
+	w.queries = append(w.queries, query)
+	w.add("text", "Other", query, "FOFA query")
+}
+
+// Close writes the event
+func (w *MISPWriter) Close() error {
+	attributes := w.attributes
+	if attributes == nil {
+		attributes = []mispAttribute{}
+	}
+	objects := w.objects
+	if objects == nil {
+		objects = []mispObject{}
+	}
+	e := json.NewEncoder(w.w)
+	e.SetIndent("", "  ")
+	if err := e.Encode(map[string]interface{}{
+		"Event": map[string]interface{}{
+			"uuid":            newUUID(),
+			"info":            "FOFA: " + strings.Join(w.queries, " || "),
+			"date":            w.date,
+			"threat_level_id": "4",
+			"analysis":        "0",
+			"distribution":    "0",
+			"Tag":             []mispTag{{Name: "tool:fofa"}},
+			"Attribute":       attributes,
+			"Object":          objects,
+		},
+	}); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// NewMISPWriter generate MISP event writer
+// fields such as ip, port, host, domain, link, protocol, certs_subject_cn, certs_subject_org, certs_issuer_cn, certs_issuer_org are used
+func NewMISPWriter(w io.Writer, fields []string) *MISPWriter {
+	return &MISPWriter{
+		w: bufio.NewWriter(w),
+		indexes: recordIndexes(fields, "ip", "host", "domain", "link", "protocol",
+			"certs_subject_cn", "certs_subject_org", "certs_issuer_cn", "certs_issuer_org", "port"),
+		date: time.Now().Format("2006-01-02"),
+		seen: make(map[string]bool),
+	}
+}
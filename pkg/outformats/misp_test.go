@@ -0,0 +1,85 @@
+package outformats
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMISPWriter(t *testing.T) {
+	var b bytes.Buffer
+	w := NewMISPWriter(&b, []string{"ip", "port", "host", "protocol", "certs_subject_cn", "certs_subject_org", "certs_issuer_cn"})
+	w.SetMeta("port=443", 10)
+	assert.Nil(t, w.WriteAll([][]string{
+		{"1.1.1.1", "443", "https://a.com", "https", "a.com", "A", "CA"},
+		{"1.1.1.1", "443", "https://a.com", "https", "a.com", "A", "CA"}, // duplicated
+		{"1.1.1.2", "22", "1.1.1.2:22", "ssh", "", "", ""},
+		{"bad", "", "", "", "", "", "CA"},
+	}))
+	// the event is written on Close
+	assert.Equal(t, 0, b.Len())
+	assert.Nil(t, w.Close())
+
+	var v struct {
+		Event struct {
+			Info      string          `json:"info"`
+			Date      string          `json:"date"`
+			Tag       []mispTag       `json:"Tag"`
+			Attribute []mispAttribute `json:"Attribute"`
+			Object    []mispObject    `json:"Object"`
+		} `json:"Event"`
+	}
+	assert.Nil(t, json.Unmarshal(b.Bytes(), &v))
+	assert.Equal(t, "FOFA: port=443", v.Event.Info)
+	assert.Equal(t, []mispTag{{Name: "tool:fofa"}}, v.Event.Tag)
+
+	var attributes [][2]string
+	for _, attr := range v.Event.Attribute {
+		attributes = append(attributes, [2]string{attr.Type, attr.Value})
+		assert.Empty(t, attr.ObjectRelation)
+	}
+	assert.Equal(t, [][2]string{
+		{"text", "port=443"},
+		{"ip-dst", "1.1.1.1"},
+		{"ip-dst|port", "1.1.1.1|443"},
+		{"domain", "a.com"},
+		{"url", "https://a.com"},
+		{"ip-dst", "1.1.1.2"},
+		{"ip-dst|port", "1.1.1.2|22"},
+	}, attributes)
+
+	// certificates are x509 objects
+	var objects [][]string
+	for _, obj := range v.Event.Object {
+		assert.Equal(t, "x509", obj.Name)
+		assert.Equal(t, "network", obj.MetaCategory)
+		var attrs []string
+		for _, attr := range obj.Attribute {
+			assert.Equal(t, "text", attr.Type)
+			attrs = append(attrs, attr.ObjectRelation+"="+attr.Value)
+		}
+		objects = append(objects, attrs)
+	}
+	assert.Equal(t, [][]string{
+		{"subject=CN=a.com, O=A", "issuer=CN=CA"},
+		{"issuer=CN=CA"},
+	}, objects)
+
+	// empty event
+	b.Reset()
+	w = NewMISPWriter(&b, []string{"title"})
+	assert.Nil(t, w.Close())
+	assert.JSONEq(t, `[]`, string(eventJSON(t, b.Bytes(), "Attribute")))
+	assert.JSONEq(t, `[]`, string(eventJSON(t, b.Bytes(), "Object")))
+}
+
+// eventJSON raw json of key of the event
+func eventJSON(t *testing.T, data []byte, key string) json.RawMessage {
+	var v struct {
+		Event map[string]json.RawMessage `json:"Event"`
+	}
+	assert.Nil(t, json.Unmarshal(data, &v))
+	return v.Event[key]
+}
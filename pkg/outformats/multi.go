@@ -0,0 +1,64 @@
+package outformats
+
+// MultiWriter duplicates records to all writers, like io.MultiWriter
+type MultiWriter struct {
+	writers []OutWriter
+}
+
+// WriteHeader writes header to each writer, stops at the first error
+func (w *MultiWriter) WriteHeader(fields []string) error {
+	for _, writer := range w.writers {
+		if err := writer.WriteHeader(fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteAll writes records to each writer, stops at the first error
+func (w *MultiWriter) WriteAll(records [][]string) error {
+	for _, writer := range w.writers {
+		if err := writer.WriteAll(records); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *MultiWriter) Flush() {
+	for _, writer := range w.writers {
+		writer.Flush()
+	}
+}
+
+// SetMeta set meta data to each writer
+func (w *MultiWriter) SetMeta(query string, size int) {
+	for _, writer := range w.writers {
+		SetMeta(writer, query, size)
+	}
+}
+
+// Files paths of files of each writer
+func (w *MultiWriter) Files() []string {
+	var files []string
+	for _, writer := range w.writers {
+		files = append(files, Files(writer)...)
+	}
+	return files
+}
+
+// Close each writer, returns the first error
+func (w *MultiWriter) Close() error {
+	var err error
+	for _, writer := range w.writers {
+		if e := Close(writer); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// NewMultiWriter generate writer duplicates records to all writers
+func NewMultiWriter(writers ...OutWriter) *MultiWriter {
+	return &MultiWriter{writers: writers}
+}
@@ -0,0 +1,223 @@
+package outformats
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type nmapState struct {
+	State  string `xml:"state,attr"`
+	Reason string `xml:"reason,attr"`
+}
+
+type nmapAddress struct {
+	Addr     string `xml:"addr,attr"`
+	AddrType string `xml:"addrtype,attr"`
+}
+
+type nmapHostname struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type nmapService struct {
+	Name   string `xml:"name,attr"`
+	Tunnel string `xml:"tunnel,attr,omitempty"`
+	Method string `xml:"method,attr"`
+	Conf   int    `xml:"conf,attr"`
+}
+
+type nmapPort struct {
+	Protocol string       `xml:"protocol,attr"`
+	PortID   int          `xml:"portid,attr"`
+	State    nmapState    `xml:"state"`
+	Service  *nmapService `xml:"service,omitempty"`
+}
+
+type nmapHost struct {
+	Status    nmapState      `xml:"status"`
+	Address   nmapAddress    `xml:"address"`
+	Hostnames []nmapHostname `xml:"hostnames>hostname"`
+	Ports     []nmapPort     `xml:"ports>port"`
+}
+
+type nmapRun struct {
+	XMLName          xml.Name   `xml:"nmaprun"`
+	Scanner          string     `xml:"scanner,attr"`
+	Args             string     `xml:"args,attr"`
+	Start            int64      `xml:"start,attr"`
+	StartStr         string     `xml:"startstr,attr"`
+	XMLOutputVersion string     `xml:"xmloutputversion,attr"`
+	Hosts            []nmapHost `xml:"host"`
+	RunStats         struct {
+		Finished struct {
+			Time    int64  `xml:"time,attr"`
+			TimeStr string `xml:"timestr,attr"`
+			Exit    string `xml:"exit,attr"`
+		} `xml:"finished"`
+		Hosts struct {
+			Up    int `xml:"up,attr"`
+			Down  int `xml:"down,attr"`
+			Total int `xml:"total,attr"`
+		} `xml:"hosts"`
+	} `xml:"runstats"`
+}
+
+// NmapWriter writes records as nmap xml, records are grouped by ip as host with open ports,
+// protocol is written as service name, host is written as hostname.
+// The document is written when Close is called.
+type NmapWriter struct {
+	noHeader
+
+	w       *bufio.Writer
+	indexes []int // index of ip, port, protocol, host, base_protocol
+	start   time.Time
+	queries []string
+	hosts   []*nmapHost
+	hostMap map[string]*nmapHost
+	seen    map[string]bool // ip, port and hostname added
+}
+
+// hostname of fofa host, empty if host is ip
+func hostname(host string) string {
+	if i := strings.Index(host, "://"); i != -1 {
+		host = host[i+3:]
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if net.ParseIP(strings.Trim(host, "[]")) != nil {
+		return ""
+	}
+	return host
+}
+
+// Write adds a single record to the host of its ip
+func (w *NmapWriter) Write(records []string) error {
+	ip := records[w.indexes[0]]
+	port, err := strconv.Atoi(records[w.indexes[1]])
+	if ip == "" || err != nil {
+		return nil
+	}
+
+	h, ok := w.hostMap[ip]
+	if !ok {
+		addrType := "ipv4"
+		if v := net.ParseIP(ip); v != nil && v.To4() == nil {
+			addrType = "ipv6"
+		}
+		h = &nmapHost{
+			Status:  nmapState{State: "up", Reason: "fofa"},
+			Address: nmapAddress{Addr: ip, AddrType: addrType},
+		}
+		w.hostMap[ip] = h
+		w.hosts = append(w.hosts, h)
+	}
+
+	if w.indexes[3] != -1 {
+		if name := hostname(records[w.indexes[3]]); name != "" && !w.seen[ip+"\t"+name] {
+			w.seen[ip+"\t"+name] = true
+			h.Hostnames = append(h.Hostnames, nmapHostname{Name: name, Type: "user"})
+		}
+	}
+
+	transport := "tcp"
+	if w.indexes[4] != -1 && records[w.indexes[4]] == "udp" {
+		transport = "udp"
+	}
+	key := ip + "\t" + transport + "\t" + strconv.Itoa(port)
+	if w.seen[key] {
+		return nil
+	}
+	w.seen[key] = true
+
+	p := nmapPort{
+		Protocol: transport,
+		PortID:   port,
+		State:    nmapState{State: "open", Reason: "fofa"},
+	}
+	if w.indexes[2] != -1 && records[w.indexes[2]] != "" {
+		p.Service = &nmapService{Name: records[w.indexes[2]], Method: "probed", Conf: 10}
+		if p.Service.Name == "https" {
+			p.Service.Name = "http"
+			p.Service.Tunnel = "ssl"
+		}
+	}
+	h.Ports = append(h.Ports, p)
+	return nil
+}
+
+// WriteAll adds multiple records using Write
+func (w *NmapWriter) WriteAll(records [][]string) error {
+	for _, record := range records {
+		err := w.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *NmapWriter) Flush() {
+	w.w.Flush()
+}
+
+// SetMeta add query to args of nmaprun
+func (w *NmapWriter) SetMeta(query string, size int) {
+	w.queries = append(w.queries, query)
+}
+
+// Close writes the nmap xml document
+func (w *NmapWriter) Close() error {
+	run := nmapRun{
+		Scanner:          "fofa",
+		Args:             strings.Join(w.queries, " || "),
+		Start:            w.start.Unix(),
+		StartStr:         w.start.Format(time.ANSIC),
+		XMLOutputVersion: "1.05",
+	}
+	for _, h := range w.hosts {
+		run.Hosts = append(run.Hosts, *h)
+	}
+	now := time.Now()
+	run.RunStats.Finished.Time = now.Unix()
+	run.RunStats.Finished.TimeStr = now.Format(time.ANSIC)
+	run.RunStats.Finished.Exit = "success"
+	run.RunStats.Hosts.Up = len(w.hosts)
+	run.RunStats.Hosts.Total = len(w.hosts)
+
+	if _, err := w.w.WriteString(xml.Header + "<!DOCTYPE nmaprun>\n"); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w.w)
+	e.Indent("", "  ")
+	if err := e.Encode(run); err != nil {
+		return err
+	}
+	if _, err := w.w.WriteString("\n"); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// NewNmapWriter generate nmap xml writer
+// fields must contain ip and port, protocol, host and base_protocol are optional
+func NewNmapWriter(w io.Writer, fields []string) (*NmapWriter, error) {
+	indexes := recordIndexes(fields, "ip", "port", "protocol", "host", "base_protocol")
+	if indexes[0] == -1 || indexes[1] == -1 {
+		return nil, errors.New("nmap format needs ip and port fields")
+	}
+	return &NmapWriter{
+		w:       bufio.NewWriter(w),
+		indexes: indexes,
+		start:   time.Now(),
+		hostMap: make(map[string]*nmapHost),
+		seen:    make(map[string]bool),
+	}, nil
+}
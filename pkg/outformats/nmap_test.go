@@ -0,0 +1,56 @@
+package outformats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostname(t *testing.T) {
+	assert.Equal(t, "a.com", hostname("a.com"))
+	assert.Equal(t, "a.com", hostname("https://a.com:8443"))
+	assert.Equal(t, "", hostname("1.1.1.1:80"))
+	assert.Equal(t, "", hostname("[::1]:80"))
+}
+
+func TestNmapWriter(t *testing.T) {
+	_, err := NewNmapWriter(&bytes.Buffer{}, []string{"ip"})
+	assert.Error(t, err)
+
+	var b bytes.Buffer
+	w, err := NewNmapWriter(&b, []string{"ip", "port", "protocol", "host", "base_protocol"})
+	assert.Nil(t, err)
+	w.SetMeta("port=80", 10)
+	assert.Nil(t, w.WriteAll([][]string{
+		{"1.1.1.1", "80", "http", "a.com", "tcp"},
+		{"1.1.1.1", "443", "https", "https://a.com", "tcp"},
+		{"1.1.1.1", "80", "http", "b.com", "tcp"}, // duplicated port, new hostname
+		{"::1", "53", "dns", "[::1]:53", "udp"},
+		{"1.1.1.2", "", "http", "", "tcp"}, // invalid port
+	}))
+	// hosts are written on Close
+	assert.Equal(t, 0, b.Len())
+	assert.Nil(t, w.Close())
+
+	var run nmapRun
+	assert.Nil(t, xml.Unmarshal(b.Bytes(), &run))
+	assert.Equal(t, "fofa", run.Scanner)
+	assert.Equal(t, "port=80", run.Args)
+	assert.Equal(t, 2, run.RunStats.Hosts.Up)
+	assert.Equal(t, 2, len(run.Hosts))
+
+	h := run.Hosts[0]
+	assert.Equal(t, nmapAddress{Addr: "1.1.1.1", AddrType: "ipv4"}, h.Address)
+	assert.Equal(t, []nmapHostname{{Name: "a.com", Type: "user"}, {Name: "b.com", Type: "user"}}, h.Hostnames)
+	assert.Equal(t, 2, len(h.Ports))
+	assert.Equal(t, 80, h.Ports[0].PortID)
+	assert.Equal(t, "http", h.Ports[0].Service.Name)
+	assert.Equal(t, &nmapService{Name: "http", Tunnel: "ssl", Method: "probed", Conf: 10}, h.Ports[1].Service)
+
+	h = run.Hosts[1]
+	assert.Equal(t, nmapAddress{Addr: "::1", AddrType: "ipv6"}, h.Address)
+	assert.Empty(t, h.Hostnames)
+	assert.Equal(t, "udp", h.Ports[0].Protocol)
+}
@@ -2,6 +2,51 @@ package outformats
 
 // OutWriter format writer interface
 type OutWriter interface {
+	WriteHeader(fields []string) error // 写入表头, formats which have field names in records ignore it
 	WriteAll(records [][]string) error // 写入
 	Flush()
 }
+
+// noHeader embedded by writers which have field names in records, such as json
+type noHeader struct{}
+
+func (noHeader) WriteHeader(fields []string) error { return nil }
+
+// MetaWriter writer which records the meta data of query, such as json-array
+type MetaWriter interface {
+	SetMeta(query string, size int) // size is the total size reported by server
+}
+
+// CloseWriter writer which needs to write the end of document, such as json-array
+type CloseWriter interface {
+	Close() error
+}
+
+// FileWriter writer which writes to files, such as rotated files
+type FileWriter interface {
+	Files() []string // paths of written files
+}
+
+// SetMeta set query meta data if writer is a MetaWriter
+func SetMeta(w OutWriter, query string, size int) {
+	if mw, ok := w.(MetaWriter); ok {
+		mw.SetMeta(query, size)
+	}
+}
+
+// Files paths of written files if writer is a FileWriter
+func Files(w OutWriter) []string {
+	if fw, ok := w.(FileWriter); ok {
+		return fw.Files()
+	}
+	return nil
+}
+
+// Close end the document if writer is a CloseWriter, otherwise just flush
+func Close(w OutWriter) error {
+	if cw, ok := w.(CloseWriter); ok {
+		return cw.Close()
+	}
+	w.Flush()
+	return nil
+}
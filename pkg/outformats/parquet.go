@@ -0,0 +1,153 @@
+package outformats
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+)
+
+// ParquetCompressions compression codecs of parquet format
+var ParquetCompressions = map[string]compress.Codec{
+	"none":   &parquet.Uncompressed,
+	"snappy": &parquet.Snappy,
+	"gzip":   &parquet.Gzip,
+	"zstd":   &parquet.Zstd,
+	"lz4":    &parquet.Lz4Raw,
+	"brotli": &parquet.Brotli,
+}
+
+// parquetNode optional column of field typed by FieldTypes
+func parquetNode(field string) parquet.Node {
+	var node parquet.Node
+	switch TypeOfField(field) {
+	case FieldTypeInt:
+		node = parquet.Int(64)
+	case FieldTypeFloat:
+		node = parquet.Leaf(parquet.DoubleType)
+	case FieldTypeBool:
+		node = parquet.Leaf(parquet.BooleanType)
+	case FieldTypeList:
+		return parquet.List(parquet.String())
+	case FieldTypeTime:
+		node = parquet.Timestamp(parquet.Millisecond)
+	default:
+		node = parquet.String()
+	}
+	return parquet.Optional(node)
+}
+
+// parquetGroup group whose columns are in order of fields, as parquet.Group sorts them by name
+type parquetGroup struct {
+	parquet.Group
+	fields []parquet.Field
+}
+
+func newParquetGroup(fields []string) *parquetGroup {
+	g := &parquetGroup{Group: parquet.Group{}}
+	for _, field := range fields {
+		node := parquetNode(field)
+		g.Group[field] = node
+		// field of a single column group, which reads the column from map rows
+		g.fields = append(g.fields, parquet.Group{field: node}.Fields()[0])
+	}
+	return g
+}
+
+func (g *parquetGroup) Fields() []parquet.Field { return g.fields }
+
+func (g *parquetGroup) GoType() reflect.Type {
+	structFields := make([]reflect.StructField, len(g.fields))
+	for i, field := range g.fields {
+		name := []rune(field.Name())
+		name[0] = unicode.ToUpper(name[0])
+		structFields[i] = reflect.StructField{Name: string(name), Type: field.GoType()}
+	}
+	return reflect.StructOf(structFields)
+}
+
+// ParquetWriter writes records as Apache Parquet, the schema is derived from fields typed by FieldTypes,
+// columns are in order of fields, lists are LIST of strings and times are timestamps in milliseconds.
+// Each WriteAll is flushed as a row group, so row groups are aligned with batches of DumpSearch.
+// The footer is written when Close is called.
+type ParquetWriter struct {
+	noHeader
+
+	fields  []string
+	w       *parquet.Writer
+	queries []string
+}
+
+func (w *ParquetWriter) row(records []string) map[string]interface{} {
+	row := make(map[string]interface{}, len(w.fields))
+	for i, field := range w.fields {
+		v := TypedValue(field, records[i])
+		switch TypeOfField(field) {
+		case FieldTypeString:
+		case FieldTypeList:
+			if v == nil {
+				v = []string{}
+			}
+		case FieldTypeTime:
+			if t, err := ParseTime(records[i]); err == nil {
+				v = t.UnixMilli()
+			} else {
+				v = nil
+			}
+		default:
+			if _, ok := v.(string); ok {
+				// failed to convert
+				v = nil
+			}
+		}
+		row[field] = v
+	}
+	return row
+}
+
+// WriteAll writes records as a row group
+func (w *ParquetWriter) WriteAll(records [][]string) error {
+	for _, record := range records {
+		if len(record) != len(w.fields) {
+			return errors.New("records length is not equal to fields")
+		}
+		if err := w.w.Write(w.row(record)); err != nil {
+			return err
+		}
+	}
+	return w.w.Flush()
+}
+
+func (w *ParquetWriter) Flush() {
+	w.w.Flush()
+}
+
+// SetMeta adds query to the key value metadata fofa.query of file
+func (w *ParquetWriter) SetMeta(query string, size int) {
+	w.queries = append(w.queries, query)
+	w.w.SetKeyValueMetadata("fofa.query", strings.Join(w.queries, " || "))
+}
+
+// Close writes the footer
+func (w *ParquetWriter) Close() error {
+	return w.w.Close()
+}
+
+// NewParquetWriter generate parquet writer, compression can be none/snappy/gzip/zstd/lz4/brotli
+func NewParquetWriter(w io.Writer, fields []string, compression string) (*ParquetWriter, error) {
+	codec, ok := ParquetCompressions[compression]
+	if !ok {
+		return nil, fmt.Errorf("unknown parquet compression: %s", compression)
+	}
+	return &ParquetWriter{
+		fields: fields,
+		w: parquet.NewWriter(w, parquet.NewSchema("fofa", newParquetGroup(fields)),
+			parquet.Compression(codec),
+			parquet.KeyValueMetadata("fofa.fields", strings.Join(fields, ","))),
+	}, nil
+}
@@ -0,0 +1,60 @@
+package outformats
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParquetWriter(t *testing.T) {
+	fields := []string{"ip", "port", "isActive", "latitude", "certs_domains", "lastupdatetime"}
+
+	var buf bytes.Buffer
+	_, err := NewParquetWriter(&buf, fields, "xz")
+	assert.Error(t, err)
+
+	w, err := NewParquetWriter(&buf, fields, "zstd")
+	assert.Nil(t, err)
+	w.SetMeta("port=80", 3)
+	assert.Nil(t, w.WriteAll([][]string{
+		{"1.1.1.1", "80", "true", "1.5", "a.com,b.com", "2024-01-10 10:00:00"},
+		{"1.1.1.2", "", "", "x", "", ""},
+	}))
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.3", "443", "false", "2", "c.com", "2024-01-11 10:00:00"}}))
+	assert.Error(t, w.WriteAll([][]string{{"1.1.1.3"}}))
+	assert.Nil(t, w.Close())
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), f.NumRows())
+	assert.Equal(t, 2, len(f.RowGroups()))
+	query, _ := f.Lookup("fofa.query")
+	assert.Equal(t, "port=80", query)
+	// columns are in order of fields, same as other formats
+	var columns []string
+	for _, field := range f.Schema().Fields() {
+		columns = append(columns, field.Name())
+	}
+	assert.Equal(t, fields, columns)
+	for i, chunk := range f.RowGroups()[0].ColumnChunks() {
+		assert.Equal(t, i, chunk.Column())
+	}
+	assert.Equal(t, []string{"port"}, f.Schema().Columns()[1])
+
+	r := parquet.NewReader(f)
+	var rows []map[string]interface{}
+	for i := 0; i < 3; i++ {
+		row := make(map[string]interface{})
+		assert.Nil(t, r.Read(&row))
+		rows = append(rows, row)
+	}
+	assert.Equal(t, "1.1.1.1", rows[0]["ip"])
+	assert.Equal(t, int64(80), rows[0]["port"])
+	assert.Equal(t, true, rows[0]["isActive"])
+	assert.Equal(t, 1.5, rows[0]["latitude"])
+	assert.Equal(t, int64(1704852000000), rows[0]["lastupdatetime"])
+	assert.Nil(t, rows[1]["port"])
+	assert.Nil(t, rows[1]["latitude"])
+}
@@ -0,0 +1,261 @@
+package outformats
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressExts extensions of compressed files
+var CompressExts = map[string]string{
+	"gzip": ".gz",
+	"zstd": ".zst",
+}
+
+// splitExt split path to stem and extension, compression extension is kept with the last one,
+// like a/b.csv.gz is a/b and .csv.gz
+func splitExt(path string) (string, string) {
+	ext := filepath.Ext(path)
+	for _, e := range CompressExts {
+		if ext == e {
+			stem := strings.TrimSuffix(path, ext)
+			return strings.TrimSuffix(stem, filepath.Ext(stem)), filepath.Ext(stem) + ext
+		}
+	}
+	return strings.TrimSuffix(path, ext), ext
+}
+
+// RotatePath path of the index-th rotated file, like a.csv.gz is a.1.csv.gz, index 0 is path itself
+func RotatePath(path string, index int) string {
+	if index == 0 {
+		return path
+	}
+	stem, ext := splitExt(path)
+	return fmt.Sprintf("%s.%d%s", stem, index, ext)
+}
+
+// compressWriter gzip or zstd writer
+type compressWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// RotateWriter writes records to file of path, which is compressed by extension .gz or .zst,
+// and rotated to files like a.1.csv when MaxRows or MaxBytes of current file is reached.
+// MaxBytes is checked after each WriteAll, the writer and the compressor are flushed before checking,
+// so a file can exceed it by the last batch. In append mode existing files are appended instead of truncated,
+// MaxBytes includes the existing size, but MaxRows only counts records written by this writer.
+// The header is written to each file which is empty when opened.
+type RotateWriter struct {
+	MaxRows  int   // max records of each file, 0 means no limit
+	MaxBytes int64 // max bytes of each file, 0 means no limit
+
+	path      string
+	append    bool
+	newWriter func(w io.Writer) (OutWriter, error)
+
+	header     []string
+	files      []string
+	index      int
+	f          *os.File
+	counter    *countWriter
+	compressor compressWriter
+	writer     OutWriter
+	rows       int
+	appended   bool // current file is not empty when opened
+
+	query   string
+	size    int
+	hasMeta bool
+}
+
+func (w *RotateWriter) open() error {
+	path := RotatePath(w.path, w.index)
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	flag := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if w.append {
+		flag = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(path, flag, 0644)
+	if err != nil {
+		return fmt.Errorf("create file %s failed: %w", path, err)
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	w.f = f
+	w.files = append(w.files, path)
+	w.counter = &countWriter{w: f, n: stat.Size()}
+	var out io.Writer = w.counter
+	w.compressor = nil
+	switch filepath.Ext(path) {
+	case CompressExts["gzip"]:
+		w.compressor = gzip.NewWriter(w.counter)
+	case CompressExts["zstd"]:
+		if w.compressor, err = zstd.NewWriter(w.counter); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if w.compressor != nil {
+		out = w.compressor
+	}
+
+	if w.writer, err = w.newWriter(out); err != nil {
+		w.closeFile()
+		return err
+	}
+	w.appended = stat.Size() > 0
+	if w.header != nil && !w.appended {
+		if err = w.writer.WriteHeader(w.header); err != nil {
+			w.closeFile()
+			return err
+		}
+	}
+	if w.hasMeta {
+		SetMeta(w.writer, w.query, w.size)
+	}
+	w.rows = 0
+	return nil
+}
+
+func (w *RotateWriter) closeFile() error {
+	var err error
+	if w.writer != nil {
+		err = Close(w.writer)
+		w.writer = nil
+	}
+	if w.compressor != nil {
+		if e := w.compressor.Close(); e != nil && err == nil {
+			err = e
+		}
+		w.compressor = nil
+	}
+	if e := w.f.Close(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
+
+func (w *RotateWriter) rotate() error {
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+	w.index++
+	return w.open()
+}
+
+// WriteHeader writes header to current file if it is empty, and each rotated file
+func (w *RotateWriter) WriteHeader(fields []string) error {
+	w.header = fields
+	if w.appended || w.rows > 0 {
+		return nil
+	}
+	return w.writer.WriteHeader(fields)
+}
+
+// fileSize bytes of current file, including those buffered in the writer and the compressor
+func (w *RotateWriter) fileSize() (int64, error) {
+	w.writer.Flush()
+	if w.compressor != nil {
+		if err := w.compressor.Flush(); err != nil {
+			return 0, err
+		}
+	}
+	return w.counter.n, nil
+}
+
+// full whether limits of current file are reached
+func (w *RotateWriter) full() (bool, error) {
+	if w.MaxRows > 0 && w.rows >= w.MaxRows {
+		return true, nil
+	}
+	if w.MaxBytes > 0 && (w.rows > 0 || w.appended) {
+		n, err := w.fileSize()
+		return n >= w.MaxBytes, err
+	}
+	return false, nil
+}
+
+// WriteAll writes records to current file, rotates when limits are reached
+func (w *RotateWriter) WriteAll(records [][]string) error {
+	for len(records) > 0 {
+		full, err := w.full()
+		if err != nil {
+			return err
+		}
+		if full {
+			if err := w.rotate(); err != nil {
+				return err
+			}
+		}
+
+		n := len(records)
+		if w.MaxRows > 0 && n > w.MaxRows-w.rows {
+			n = w.MaxRows - w.rows
+		}
+		if err := w.writer.WriteAll(records[:n]); err != nil {
+			return err
+		}
+		w.rows += n
+		records = records[n:]
+	}
+	return nil
+}
+
+func (w *RotateWriter) Flush() {
+	w.writer.Flush()
+}
+
+// SetMeta set meta data to current writer, and writers of rotated files
+func (w *RotateWriter) SetMeta(query string, size int) {
+	w.query, w.size, w.hasMeta = query, size, true
+	SetMeta(w.writer, query, size)
+}
+
+// Files paths of the file and rotated files
+func (w *RotateWriter) Files() []string {
+	return w.files
+}
+
+// Close current file
+func (w *RotateWriter) Close() error {
+	return w.closeFile()
+}
+
+// NewRotateWriter generate writer of file path, newWriter generates writer of each file
+func NewRotateWriter(path string, append bool, newWriter func(w io.Writer) (OutWriter, error)) (*RotateWriter, error) {
+	w := &RotateWriter{
+		path:      path,
+		append:    append,
+		newWriter: newWriter,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
@@ -0,0 +1,117 @@
+package outformats
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCSVWriter(w io.Writer) (OutWriter, error) {
+	return NewCSVWriter(w), nil
+}
+
+func readGzip(t *testing.T, path string) string {
+	f, err := os.Open(path)
+	assert.Nil(t, err)
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	assert.Nil(t, err)
+	d, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	return string(d)
+}
+
+func TestRotatePath(t *testing.T) {
+	assert.Equal(t, "a.csv", RotatePath("a.csv", 0))
+	assert.Equal(t, "a.1.csv", RotatePath("a.csv", 1))
+	assert.Equal(t, "dir/a.b.2.csv.gz", RotatePath("dir/a.b.csv.gz", 2))
+	assert.Equal(t, "a.3.zst", RotatePath("a.zst", 3))
+	assert.Equal(t, "a.1", RotatePath("a", 1))
+}
+
+func TestRotateWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.csv.gz")
+
+	w, err := NewRotateWriter(path, false, newTestCSVWriter)
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteHeader([]string{"ip", "port"}))
+	w.MaxRows = 2
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80"}, {"1.1.1.2", "80"}, {"1.1.1.3", "80"}}))
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.4", "80"}}))
+	assert.Nil(t, w.Close())
+	assert.Equal(t, []string{path, filepath.Join(dir, "a.1.csv.gz")}, Files(w))
+
+	assert.Equal(t, "ip,port\n1.1.1.1,80\n1.1.1.2,80\n", readGzip(t, path))
+	assert.Equal(t, "ip,port\n1.1.1.3,80\n1.1.1.4,80\n", readGzip(t, filepath.Join(dir, "a.1.csv.gz")))
+
+	// append without headline
+	w, err = NewRotateWriter(path, true, newTestCSVWriter)
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteHeader([]string{"ip", "port"}))
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.5", "80"}}))
+	assert.Nil(t, w.Close())
+	assert.Equal(t, "ip,port\n1.1.1.1,80\n1.1.1.2,80\n1.1.1.5,80\n", readGzip(t, path))
+
+	// rotate by bytes after each WriteAll
+	path = filepath.Join(dir, "b.csv")
+	w, err = NewRotateWriter(path, false, newTestCSVWriter)
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteHeader([]string{"ip", "port"}))
+	w.MaxBytes = 10
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80"}}))
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.2", "80"}}))
+	assert.Nil(t, w.Close())
+	d, err := os.ReadFile(filepath.Join(dir, "b.1.csv"))
+	assert.Nil(t, err)
+	assert.Equal(t, "ip,port\n1.1.1.2,80\n", string(d))
+
+	// rotate by compressed bytes, buffered data of compressor is counted
+	path = filepath.Join(dir, "c.csv.gz")
+	w, err = NewRotateWriter(path, false, newTestCSVWriter)
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteHeader([]string{"ip", "port"}))
+	w.MaxBytes = 20
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80"}, {"1.1.1.2", "80"}}))
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.3", "80"}}))
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.4", "80"}}))
+	assert.Nil(t, w.Close())
+	assert.Equal(t, []string{path, filepath.Join(dir, "c.1.csv.gz"), filepath.Join(dir, "c.2.csv.gz")}, Files(w))
+	assert.Equal(t, "ip,port\n1.1.1.1,80\n1.1.1.2,80\n", readGzip(t, path))
+	assert.Equal(t, "ip,port\n1.1.1.3,80\n", readGzip(t, filepath.Join(dir, "c.1.csv.gz")))
+	assert.Equal(t, "ip,port\n1.1.1.4,80\n", readGzip(t, filepath.Join(dir, "c.2.csv.gz")))
+}
+
+func TestDirWriter(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	w := NewDirWriter(dir, ".csv", false, func(path string) (OutWriter, error) {
+		return NewRotateWriter(path, false, newTestCSVWriter)
+	})
+	assert.Nil(t, w.WriteHeader([]string{"ip", "port"}))
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80"}}))
+	w.SetMeta(`ip="1.1.1.1"`, 1)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80"}}))
+	w.SetMeta(`ip=1.1.1.1`, 1)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80"}}))
+	w.SetMeta(`"`, 0)
+	assert.Nil(t, w.Close())
+
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"0001.csv", "0004.csv", "ip_1_1_1_1.csv", "ip_1_1_1_1_3.csv"}, names)
+	assert.Len(t, Files(w), 4)
+	d, err := os.ReadFile(filepath.Join(dir, "ip_1_1_1_1.csv"))
+	assert.Nil(t, err)
+	assert.Equal(t, "ip,port\n1.1.1.1,80\n", string(d))
+
+	assert.Equal(t, "ip_1_1_1_1", SanitizeName(`ip="1.1.1.1"`, 100))
+	assert.Equal(t, "app_Redis", SanitizeName(`app="Redis" && port="6379"`, 9))
+}
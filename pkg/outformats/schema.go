@@ -0,0 +1,89 @@
+package outformats
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType value type of fofa field
+type FieldType int
+
+const (
+	FieldTypeString FieldType = iota // default type
+	FieldTypeInt                     // like port, asn
+	FieldTypeFloat                   // like latitude, longitude
+	FieldTypeBool                    // like isActive
+	FieldTypeList                    // comma separated, like certs_domains
+	FieldTypeTime                    // like lastupdatetime
+)
+
+// FofaTimeLayout time layout of fofa time fields, such as lastupdatetime
+const FofaTimeLayout = "2006-01-02 15:04:05"
+
+// FofaTimeLocation fofa server returns time in China Standard Time
+var FofaTimeLocation = time.FixedZone("CST", 8*3600)
+
+// FieldTypes schema of fofa fields, fields not included are FieldTypeString
+var FieldTypes = map[string]FieldType{
+	"port":           FieldTypeInt,
+	"asn":            FieldTypeInt,
+	"status_code":    FieldTypeInt,
+	"latitude":       FieldTypeFloat,
+	"longitude":      FieldTypeFloat,
+	"isActive":       FieldTypeBool,
+	"certs_valid":    FieldTypeBool,
+	"certs_domains":  FieldTypeList,
+	"protocol":       FieldTypeList,
+	"lastupdatetime": FieldTypeTime,
+}
+
+// TypeOfField type of field in schema
+func TypeOfField(field string) FieldType {
+	if t, ok := FieldTypes[field]; ok {
+		return t
+	}
+	return FieldTypeString
+}
+
+// ParseTime parse fofa time string
+func ParseTime(value string) (time.Time, error) {
+	return time.ParseInLocation(FofaTimeLayout, value, FofaTimeLocation)
+}
+
+// TypedValue convert value to the type of field,
+// empty value of non-string field is nil, keep string if failed to convert
+func TypedValue(field string, value string) interface{} {
+	t := TypeOfField(field)
+	if t != FieldTypeString && value == "" {
+		return nil
+	}
+
+	switch t {
+	case FieldTypeInt:
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v
+		}
+	case FieldTypeFloat:
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v
+		}
+	case FieldTypeBool:
+		if v, err := strconv.ParseBool(value); err == nil {
+			return v
+		}
+	case FieldTypeList:
+		var list []string
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); len(v) > 0 {
+				list = append(list, v)
+			}
+		}
+		return list
+	case FieldTypeTime:
+		if v, err := ParseTime(value); err == nil {
+			return v.Format(time.RFC3339)
+		}
+	}
+	return value
+}
@@ -0,0 +1,34 @@
+package outformats
+
+import "time"
+
+// retry calls fn at most retries+1 times until it succeeds or returns an error which is not retryable,
+// the wait before each retry is increased by attempts
+func retry(retries int, wait time.Duration, fn func() (retryable bool, err error)) error {
+	var err error
+	var retryable bool
+	for i := 0; i <= retries; i++ {
+		if i > 0 {
+			time.Sleep(wait * time.Duration(i))
+		}
+		if retryable, err = fn(); err == nil || !retryable {
+			break
+		}
+	}
+	return err
+}
+
+// eachBatch calls fn with records split into batches of at most size records, size 0 means no split
+func eachBatch(records [][]string, size int, fn func([][]string) error) error {
+	for len(records) > 0 {
+		n := len(records)
+		if size > 0 && n > size {
+			n = size
+		}
+		if err := fn(records[:n]); err != nil {
+			return err
+		}
+		records = records[n:]
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package outformats
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteMetaTable table of queries written to sqlite database
+const SQLiteMetaTable = "fofa_meta"
+
+// SQLiteWriter writes records to a table of sqlite database,
+// columns are typed by FieldTypes: int as INTEGER, float as REAL, bool as INTEGER 0/1, others as TEXT.
+// If perQuery is set, each query set by SetMeta is written to a new table named <table>_<n>.
+// Each query is a row of table fofa_meta with table_name, query, fields, size, rows, account and created_at.
+type SQLiteWriter struct {
+	noHeader
+
+	Account string // account of queries in fofa_meta
+
+	db       *sql.DB
+	path     string
+	fields   []string
+	table    string
+	perQuery bool
+	tables   int    // count of created tables
+	current  string // current table
+	metaID   int64  // row id of current query in fofa_meta, 0 means no query
+	err      error  // error of SetMeta, returned by next WriteAll
+}
+
+// quoteIdent quote sqlite identifier
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// sqliteColumnType column type of field
+func sqliteColumnType(field string) string {
+	switch TypeOfField(field) {
+	case FieldTypeInt, FieldTypeBool:
+		return "INTEGER"
+	case FieldTypeFloat:
+		return "REAL"
+	default:
+		return "TEXT"
+	}
+}
+
+// sqliteValue value of field, empty value of non-string field is NULL
+func sqliteValue(field string, value string) interface{} {
+	t := TypeOfField(field)
+	if t != FieldTypeString && value == "" {
+		return nil
+	}
+	switch t {
+	case FieldTypeInt:
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v
+		}
+	case FieldTypeFloat:
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v
+		}
+	case FieldTypeBool:
+		if v, err := strconv.ParseBool(value); err == nil {
+			if v {
+				return 1
+			}
+			return 0
+		}
+	}
+	return value
+}
+
+func (w *SQLiteWriter) createTable(name string) error {
+	var columns []string
+	for _, field := range w.fields {
+		columns = append(columns, quoteIdent(field)+" "+sqliteColumnType(field))
+	}
+	_, err := w.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", quoteIdent(name), strings.Join(columns, ", ")))
+	if err != nil {
+		return fmt.Errorf("create table %s failed: %w", name, err)
+	}
+	w.tables++
+	w.current = name
+	return nil
+}
+
+// nextTable create table of next query in perQuery mode, or the table of run
+func (w *SQLiteWriter) nextTable() error {
+	if !w.perQuery {
+		if w.current != "" {
+			return nil
+		}
+		return w.createTable(w.table)
+	}
+	return w.createTable(fmt.Sprintf("%s_%d", w.table, w.tables+1))
+}
+
+func (w *SQLiteWriter) addMeta(query string, size int) error {
+	res, err := w.db.Exec("INSERT INTO "+SQLiteMetaTable+
+		" (table_name, query, fields, size, rows, account, created_at) VALUES (?, ?, ?, ?, 0, ?, ?)",
+		w.current, query, strings.Join(w.fields, ","), size, w.Account, time.Now().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	w.metaID, err = res.LastInsertId()
+	return err
+}
+
+// SetMeta starts a query, which is written to a new table in perQuery mode
+func (w *SQLiteWriter) SetMeta(query string, size int) {
+	if w.metaID != 0 || w.current == "" {
+		if w.err = w.nextTable(); w.err != nil {
+			return
+		}
+	}
+	w.err = w.addMeta(query, size)
+}
+
+// WriteAll writes records in a transaction
+func (w *SQLiteWriter) WriteAll(records [][]string) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.current == "" {
+		if err := w.nextTable(); err != nil {
+			return err
+		}
+	}
+	if w.metaID == 0 {
+		if err := w.addMeta("", 0); err != nil {
+			return err
+		}
+	}
+
+	tx, err := w.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(w.fields)), ", ")
+	stmt, err := tx.Prepare(fmt.Sprintf("INSERT INTO %s VALUES (%s)", quoteIdent(w.current), placeholders))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	args := make([]interface{}, len(w.fields))
+	for _, record := range records {
+		if len(record) != len(w.fields) {
+			return errors.New("records length is not equal to fields")
+		}
+		for i, field := range w.fields {
+			args[i] = sqliteValue(field, record[i])
+		}
+		if _, err = stmt.Exec(args...); err != nil {
+			return err
+		}
+	}
+	if _, err = tx.Exec("UPDATE "+SQLiteMetaTable+" SET rows = rows + ? WHERE id = ?", len(records), w.metaID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (w *SQLiteWriter) Flush() {
+}
+
+// Files path of the database
+func (w *SQLiteWriter) Files() []string {
+	return []string{w.path}
+}
+
+// Close the database
+func (w *SQLiteWriter) Close() error {
+	return w.db.Close()
+}
+
+// NewSQLiteWriter generate writer of sqlite database file, which is created if not exists.
+// table is the name of table, default is fofa_<time> of each run
+func NewSQLiteWriter(path string, fields []string, table string, perQuery bool) (*SQLiteWriter, error) {
+	if len(table) == 0 {
+		table = "fofa_" + time.Now().Format("20060102_150405")
+	}
+	if table == SQLiteMetaTable {
+		return nil, fmt.Errorf("table name %s is reserved", table)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS " + SQLiteMetaTable + ` (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	table_name TEXT,
+	query TEXT,
+	fields TEXT,
+	size INTEGER,
+	rows INTEGER,
+	account TEXT,
+	created_at TEXT
+)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open sqlite %s failed: %w", path, err)
+	}
+
+	return &SQLiteWriter{
+		db:       db,
+		path:     path,
+		fields:   fields,
+		table:    table,
+		perQuery: perQuery,
+	}, nil
+}
@@ -0,0 +1,72 @@
+package outformats
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fofa.db")
+	fields := []string{"ip", "port", "isActive", "latitude"}
+
+	_, err := NewSQLiteWriter(path, fields, SQLiteMetaTable, false)
+	assert.Error(t, err)
+
+	// one table of run
+	w, err := NewSQLiteWriter(path, fields, "assets", false)
+	assert.Nil(t, err)
+	w.Account = "a@b.c"
+	w.SetMeta("port=80", 10)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80", "true", "1.5"}, {"1.1.1.2", "", "", ""}}))
+	w.SetMeta("port=443", 20)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.3", "443", "false", "2"}}))
+	assert.Error(t, w.WriteAll([][]string{{"1.1.1.3"}}))
+	assert.Nil(t, w.Close())
+
+	// one table of each query, appended to the same file
+	w, err = NewSQLiteWriter(path, fields, "q", true)
+	assert.Nil(t, err)
+	w.SetMeta("port=22", 1)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.4", "22", "true", ""}}))
+	w.SetMeta("port=21", 1)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.5", "21", "true", ""}}))
+	assert.Nil(t, w.Close())
+
+	db, err := sql.Open("sqlite", path)
+	assert.Nil(t, err)
+	defer db.Close()
+
+	var port, active sql.NullInt64
+	var latitude sql.NullFloat64
+	assert.Nil(t, db.QueryRow(`SELECT port, isActive, latitude FROM assets WHERE ip = '1.1.1.1'`).Scan(&port, &active, &latitude))
+	assert.Equal(t, int64(80), port.Int64)
+	assert.Equal(t, int64(1), active.Int64)
+	assert.Equal(t, 1.5, latitude.Float64)
+	assert.Nil(t, db.QueryRow(`SELECT port, isActive, latitude FROM assets WHERE ip = '1.1.1.2'`).Scan(&port, &active, &latitude))
+	assert.False(t, port.Valid)
+	assert.False(t, latitude.Valid)
+
+	rows, err := db.Query(`SELECT table_name, query, size, rows, account FROM ` + SQLiteMetaTable + ` ORDER BY id`)
+	assert.Nil(t, err)
+	defer rows.Close()
+	type meta struct {
+		table, query string
+		size, rows   int
+		account      string
+	}
+	var metas []meta
+	for rows.Next() {
+		var m meta
+		assert.Nil(t, rows.Scan(&m.table, &m.query, &m.size, &m.rows, &m.account))
+		metas = append(metas, m)
+	}
+	assert.Equal(t, []meta{
+		{"assets", "port=80", 10, 2, "a@b.c"},
+		{"assets", "port=443", 20, 1, "a@b.c"},
+		{"q_1", "port=22", 1, 1, ""},
+		{"q_2", "port=21", 1, 1, ""},
+	}, metas)
+}
@@ -0,0 +1,293 @@
+package outformats
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// stixNamespace namespace of deterministic identifiers of STIX cyber-observable objects
+var stixNamespace = [16]byte{0x00, 0xab, 0xed, 0xb4, 0xaa, 0x42, 0x46, 0x6c, 0x9c, 0x01, 0xfe, 0xd2, 0x33, 0x15, 0xa9, 0xb7}
+
+func formatUUID(b []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// newUUID random uuid v4
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return formatUUID(b[:])
+}
+
+// uuid5 name based uuid v5 of namespace
+func uuid5(namespace [16]byte, name string) string {
+	h := sha1.New()
+	h.Write(namespace[:])
+	h.Write([]byte(name))
+	b := h.Sum(nil)[:16]
+	b[6] = (b[6] & 0x0f) | 0x50
+	b[8] = (b[8] & 0x3f) | 0x80
+	return formatUUID(b)
+}
+
+// recordHost hostname of host or domain field, empty if it is ip
+func recordHost(records []string, hostIndex, domainIndex int) string {
+	if hostIndex != -1 {
+		if name := hostname(records[hostIndex]); name != "" {
+			return name
+		}
+	}
+	if domainIndex != -1 {
+		return records[domainIndex]
+	}
+	return ""
+}
+
+// recordURL link field, or url fixed from host field
+func recordURL(records []string, linkIndex, hostIndex, protocolIndex int) string {
+	if linkIndex != -1 && records[linkIndex] != "" {
+		return records[linkIndex]
+	}
+	if hostIndex == -1 || records[hostIndex] == "" {
+		return ""
+	}
+	protocol := ""
+	if protocolIndex != -1 {
+		protocol = records[protocolIndex]
+	}
+	if protocol != "" && protocol != "http" && protocol != "https" && !strings.Contains(records[hostIndex], "://") {
+		return ""
+	}
+	return FixURL(records[hostIndex], protocol, "")
+}
+
+// recordCert subject and issuer of certificate fields
+func recordCert(records []string, indexes []int) (subject string, issuer string) {
+	var subjects, issuers []string
+	for i, prefix := range []string{"CN=", "O=", "CN=", "O="} {
+		if indexes[i] == -1 || records[indexes[i]] == "" {
+			continue
+		}
+		if i < 2 {
+			subjects = append(subjects, prefix+records[indexes[i]])
+		} else {
+			issuers = append(issuers, prefix+records[indexes[i]])
+		}
+	}
+	return strings.Join(subjects, ", "), strings.Join(issuers, ", ")
+}
+
+var certSerialNumber = regexp.MustCompile(`(?m)^\s*Serial Number:\s*(\S+)`)
+
+// recordCertSerial serial number in certificate text of cert field, like Serial Number: 1234
+func recordCertSerial(records []string, certIndex int) string {
+	if certIndex == -1 {
+		return ""
+	}
+	if m := certSerialNumber.FindStringSubmatch(records[certIndex]); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// STIXWriter writes records and queries as a STIX 2.1 bundle:
+// an indicator of each query with pattern_type fofa,
+// ipv4-addr/ipv6-addr/domain-name/url/x509-certificate observables of records,
+// and relationships between them. The bundle is written when Close is called.
+type STIXWriter struct {
+	noHeader
+
+	w       *bufio.Writer
+	indexes []int // index of ip, host, domain, link, protocol, certs_subject_cn, certs_subject_org, certs_issuer_cn, certs_issuer_org, cert
+	now     string
+	objects []map[string]interface{}
+	ids     map[string]bool // added objects
+	records []string        // ids of observables of all records, related to indicators
+	related map[string]bool // observables in records
+}
+
+func (w *STIXWriter) add(obj map[string]interface{}) string {
+	id := obj["id"].(string)
+	if !w.ids[id] {
+		w.ids[id] = true
+		w.objects = append(w.objects, obj)
+	}
+	return id
+}
+
+func (w *STIXWriter) addObservable(typ string, value string) string {
+	d, _ := json.Marshal(map[string]string{"value": value})
+	id := w.add(map[string]interface{}{
+		"type":         typ,
+		"spec_version": "2.1",
+		"id":           typ + "--" + uuid5(stixNamespace, string(d)),
+		"value":        value,
+	})
+	if !w.related[id] {
+		w.related[id] = true
+		w.records = append(w.records, id)
+	}
+	return id
+}
+
+func (w *STIXWriter) addRelationship(typ string, source string, target string) {
+	w.add(map[string]interface{}{
+		"type":              "relationship",
+		"spec_version":      "2.1",
+		"id":                "relationship--" + uuid5(stixNamespace, typ+source+target),
+		"created":           w.now,
+		"modified":          w.now,
+		"relationship_type": typ,
+		"source_ref":        source,
+		"target_ref":        target,
+	})
+}
+
+// Write adds observables and relationships of a single record
+func (w *STIXWriter) Write(records []string) error {
+	var ipID string
+	if w.indexes[0] != -1 {
+		if ip := net.ParseIP(records[w.indexes[0]]); ip != nil {
+			typ := "ipv4-addr"
+			if ip.To4() == nil {
+				typ = "ipv6-addr"
+			}
+			ipID = w.addObservable(typ, ip.String())
+		}
+	}
+
+	var domainID string
+	if name := recordHost(records, w.indexes[1], w.indexes[2]); name != "" {
+		domainID = w.addObservable("domain-name", name)
+		if ipID != "" {
+			w.addRelationship("resolves-to", domainID, ipID)
+		}
+	}
+
+	if u := recordURL(records, w.indexes[3], w.indexes[1], w.indexes[4]); u != "" {
+		urlID := w.addObservable("url", u)
+		if domainID != "" {
+			w.addRelationship("related-to", urlID, domainID)
+		} else if ipID != "" {
+			w.addRelationship("related-to", urlID, ipID)
+		}
+	}
+
+	serial := recordCertSerial(records, w.indexes[9])
+	if subject, issuer := recordCert(records, w.indexes[5:9]); subject != "" || issuer != "" || serial != "" {
+		obj := map[string]interface{}{
+			"type":         "x509-certificate",
+			"spec_version": "2.1",
+		}
+		// id由serial_number生成，没有时由subject和issuer生成，相同证书每次导出的id相同
+		contributing := map[string]string{"serial_number": serial}
+		if serial != "" {
+			obj["serial_number"] = serial
+		} else {
+			contributing = map[string]string{"subject": subject, "issuer": issuer}
+		}
+		if subject != "" {
+			obj["subject"] = subject
+		}
+		if issuer != "" {
+			obj["issuer"] = issuer
+		}
+		d, _ := json.Marshal(contributing)
+		obj["id"] = "x509-certificate--" + uuid5(stixNamespace, string(d))
+		certID := w.add(obj)
+		if !w.related[certID] {
+			w.related[certID] = true
+			w.records = append(w.records, certID)
+		}
+		if domainID != "" {
+			w.addRelationship("related-to", certID, domainID)
+		} else if ipID != "" {
+			w.addRelationship("related-to", certID, ipID)
+		}
+	}
+	return nil
+}
+
+// WriteAll adds multiple records using Write
+func (w *STIXWriter) WriteAll(records [][]string) error {
+	for _, record := range records {
+		err := w.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *STIXWriter) Flush() {
+	w.w.Flush()
+}
+
+// SetMeta adds an indicator of the query, observables are related to all indicators
+func (w *STIXWriter) SetMeta(query string, size int) {
+	w.add(map[string]interface{}{
+		"type":         "indicator",
+		"spec_version": "2.1",
+		"id":           "indicator--" + uuid5(stixNamespace, "fofa:"+query),
+		"created":      w.now,
+		"modified":     w.now,
+		"name":         "FOFA query: " + query,
+		"description":  fmt.Sprintf("FOFA query matched %d assets", size),
+		"pattern":      query,
+		"pattern_type": "fofa",
+		"valid_from":   w.now,
+	})
+}
+
+// Close writes the bundle
+func (w *STIXWriter) Close() error {
+	var indicators []string
+	for _, obj := range w.objects {
+		if obj["type"] == "indicator" {
+			indicators = append(indicators, obj["id"].(string))
+		}
+	}
+	for _, indicator := range indicators {
+		for _, id := range w.records {
+			w.addRelationship("related-to", indicator, id)
+		}
+	}
+
+	objects := w.objects
+	if objects == nil {
+		objects = []map[string]interface{}{}
+	}
+	e := json.NewEncoder(w.w)
+	e.SetIndent("", "  ")
+	if err := e.Encode(map[string]interface{}{
+		"type":    "bundle",
+		"id":      "bundle--" + newUUID(),
+		"objects": objects,
+	}); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// NewSTIXWriter generate STIX 2.1 bundle writer
+// fields such as ip, host, domain, link, protocol, certs_subject_cn, certs_subject_org, certs_issuer_cn, certs_issuer_org, cert are used
+func NewSTIXWriter(w io.Writer, fields []string) *STIXWriter {
+	return &STIXWriter{
+		w: bufio.NewWriter(w),
+		indexes: recordIndexes(fields, "ip", "host", "domain", "link", "protocol",
+			"certs_subject_cn", "certs_subject_org", "certs_issuer_cn", "certs_issuer_org", "cert"),
+		now:     time.Now().UTC().Format("2006-01-02T15:04:05.000Z"),
+		ids:     make(map[string]bool),
+		related: make(map[string]bool),
+	}
+}
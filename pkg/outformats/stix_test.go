@@ -0,0 +1,124 @@
+package outformats
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stixBundle write records and query by STIXWriter, and parse the bundle
+func stixBundle(t *testing.T, fields []string, query string, records [][]string) (objects []map[string]interface{}) {
+	var b bytes.Buffer
+	w := NewSTIXWriter(&b, fields)
+	w.SetMeta(query, len(records))
+	assert.Nil(t, w.WriteAll(records))
+	assert.Nil(t, w.Close())
+
+	var bundle struct {
+		Type    string                   `json:"type"`
+		ID      string                   `json:"id"`
+		Objects []map[string]interface{} `json:"objects"`
+	}
+	assert.Nil(t, json.Unmarshal(b.Bytes(), &bundle))
+	assert.Equal(t, "bundle", bundle.Type)
+	assert.True(t, strings.HasPrefix(bundle.ID, "bundle--"))
+	return bundle.Objects
+}
+
+// stixIDs ids of objects
+func stixIDs(objects []map[string]interface{}) []string {
+	var ids []string
+	for _, obj := range objects {
+		ids = append(ids, obj["id"].(string))
+	}
+	return ids
+}
+
+func TestUUID5(t *testing.T) {
+	// same as uuid.uuid5 of python with the STIX namespace
+	assert.Equal(t, "ipv4-addr--28bb3599-77cd-5a82-a950-b5bc3caf07c4", "ipv4-addr--"+uuid5(stixNamespace, `{"value":"198.51.100.3"}`))
+	assert.Equal(t, 36, len(newUUID()))
+	assert.NotEqual(t, newUUID(), newUUID())
+}
+
+func TestSTIXWriter(t *testing.T) {
+	fields := []string{"ip", "host", "protocol", "certs_subject_cn", "certs_issuer_cn"}
+	records := [][]string{
+		{"1.1.1.1", "a.com", "http", "a.com", "CA"},
+		{"1.1.1.1", "https://a.com", "https", "a.com", "CA"},
+		{"::1", "[::1]:22", "ssh", "", ""},
+	}
+	objects := stixBundle(t, fields, "port=80", records)
+
+	byID := make(map[string]map[string]interface{})
+	types := make(map[string]int)
+	for _, obj := range objects {
+		byID[obj["id"].(string)] = obj
+		types[obj["type"].(string)]++
+	}
+	assert.Equal(t, len(objects), len(byID))
+	assert.Equal(t, map[string]int{
+		"indicator":        1,
+		"ipv4-addr":        1,
+		"ipv6-addr":        1,
+		"domain-name":      1,
+		"url":              2,
+		"x509-certificate": 1,
+		// resolves-to, url and certificate to domain, indicator to 6 observables
+		"relationship": 1 + 2 + 1 + 6,
+	}, types)
+
+	// relationships refer to objects of bundle
+	for _, obj := range objects {
+		if obj["type"] == "relationship" {
+			assert.Contains(t, byID, obj["source_ref"])
+			assert.Contains(t, byID, obj["target_ref"])
+		}
+	}
+
+	ipID := "ipv4-addr--" + uuid5(stixNamespace, `{"value":"1.1.1.1"}`)
+	domainID := "domain-name--" + uuid5(stixNamespace, `{"value":"a.com"}`)
+	assert.Contains(t, byID, ipID)
+	assert.Contains(t, byID, domainID)
+	assert.Contains(t, byID, "relationship--"+uuid5(stixNamespace, "resolves-to"+domainID+ipID))
+	for _, obj := range objects {
+		if obj["type"] == "x509-certificate" {
+			assert.Equal(t, "CN=a.com", obj["subject"])
+			assert.Equal(t, "CN=CA", obj["issuer"])
+		}
+	}
+
+	// ids are deterministic
+	assert.Equal(t, stixIDs(objects), stixIDs(stixBundle(t, fields, "port=80", records)))
+	certID := "x509-certificate--" + uuid5(stixNamespace, `{"issuer":"CN=CA","subject":"CN=a.com"}`)
+	assert.Contains(t, byID, certID)
+	assert.Contains(t, byID, "relationship--"+uuid5(stixNamespace, "related-to"+certID+domainID))
+
+	// id of certificate with serial number
+	objects = stixBundle(t, []string{"ip", "certs_subject_cn", "cert"}, "port=443", [][]string{
+		{"1.1.1.1", "a.com", "Version:  v3\nSerial Number: 1234567890\nSignature Algorithm: SHA256-RSA\n"},
+		{"1.1.1.2", "", "Version:  v3\nSerial Number: 1234567890\n"},
+	})
+	var certs []map[string]interface{}
+	for _, obj := range objects {
+		if obj["type"] == "x509-certificate" {
+			certs = append(certs, obj)
+		}
+	}
+	assert.Equal(t, []map[string]interface{}{{
+		"type":          "x509-certificate",
+		"spec_version":  "2.1",
+		"id":            "x509-certificate--" + uuid5(stixNamespace, `{"serial_number":"1234567890"}`),
+		"serial_number": "1234567890",
+		"subject":       "CN=a.com",
+	}}, certs)
+
+	// no records
+	objects = stixBundle(t, []string{"title"}, "title=a", [][]string{{"a"}})
+	assert.Equal(t, 1, len(objects))
+	assert.Equal(t, "indicator--"+uuid5(stixNamespace, "fofa:title=a"), objects[0]["id"])
+	assert.Equal(t, "fofa", objects[0]["pattern_type"])
+}
@@ -0,0 +1,209 @@
+package outformats
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// URLSchemes protocols used as url scheme directly, others are fixed as http
+var URLSchemes = []string{"socks5", "redis", "http", "https", "mongodb", "mysql"}
+
+// FixURL fix host as url, host contains :// will not change
+// urlPrefix is used if set, otherwise protocol is used as scheme if in URLSchemes, default is http://
+func FixURL(host string, protocol string, urlPrefix string) string {
+	if strings.Contains(host, "://") {
+		return host
+	}
+	if urlPrefix != "" {
+		return urlPrefix + host
+	}
+	if slices.Contains(URLSchemes, protocol) {
+		return protocol + "://" + host
+	}
+	return "http://" + host
+}
+
+// recordIndexes index of fields, -1 means not exists
+func recordIndexes(fields []string, names ...string) []int {
+	var indexes []int
+	for _, name := range names {
+		indexes = append(indexes, slices.Index(fields, name))
+	}
+	return indexes
+}
+
+// recordTarget host and protocol of record, host is ip:port if host field not exists
+func recordTarget(records []string, hostIndex, ipIndex, portIndex, protocolIndex int) (host string, protocol string) {
+	if protocolIndex != -1 {
+		protocol = records[protocolIndex]
+	}
+	if hostIndex != -1 {
+		host = records[hostIndex]
+	}
+	if host == "" && ipIndex != -1 {
+		host = records[ipIndex]
+		if portIndex != -1 && records[portIndex] != "" {
+			host += ":" + records[portIndex]
+		}
+	}
+	return
+}
+
+var invalidFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// TargetsWriter writes targets to one file per protocol in dir, like http_targets.txt, redis_targets.txt.
+// Targets of protocols in URLSchemes are written as url, others are written as host
+type TargetsWriter struct {
+	noHeader
+
+	dir       string
+	appendOut bool  // append to existing files instead of truncating them
+	indexes   []int // index of host, ip, port, protocol
+	files     map[string]*os.File
+	writers   map[string]*bufio.Writer
+	seen      map[string]bool // written targets
+}
+
+// targetsFileName name of protocol used in file name, unknown if empty
+func targetsFileName(protocol string) string {
+	if protocol == "" {
+		return "unknown"
+	}
+	return invalidFileNameChars.ReplaceAllString(protocol, "_")
+}
+
+func (w *TargetsWriter) writer(protocol string) (*bufio.Writer, error) {
+	protocol = targetsFileName(protocol)
+	if bw, ok := w.writers[protocol]; ok {
+		return bw, nil
+	}
+
+	path := filepath.Join(w.dir, protocol+"_targets.txt")
+	flag := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if w.appendOut {
+		flag = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		// 已有的目标不再重复写入
+		if err := w.loadSeen(path, protocol); err != nil {
+			return nil, err
+		}
+	}
+	f, err := os.OpenFile(path, flag, 0644)
+	if err != nil {
+		return nil, err
+	}
+	w.files[protocol] = f
+	w.writers[protocol] = bufio.NewWriter(f)
+	return w.writers[protocol], nil
+}
+
+// loadSeen mark targets of existing file of protocol as written
+func (w *TargetsWriter) loadSeen(path string, protocol string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if target := strings.TrimSpace(scanner.Text()); target != "" {
+			w.seen[protocol+"\t"+target] = true
+		}
+	}
+	return scanner.Err()
+}
+
+// Write writes target of a single record to the file of its protocol, duplicated targets are ignored.
+func (w *TargetsWriter) Write(records []string) error {
+	host, protocol := recordTarget(records, w.indexes[0], w.indexes[1], w.indexes[2], w.indexes[3])
+	if host == "" {
+		return nil
+	}
+	target := host
+	if slices.Contains(URLSchemes, protocol) {
+		target = FixURL(host, protocol, "")
+	}
+
+	// 先打开文件，追加时会载入已有的目标
+	bw, err := w.writer(protocol)
+	if err != nil {
+		return err
+	}
+	key := targetsFileName(protocol) + "\t" + target
+	if w.seen[key] {
+		return nil
+	}
+	w.seen[key] = true
+	_, err = fmt.Fprintln(bw, target)
+	return err
+}
+
+// WriteAll writes multiple records using Write and then calls Flush.
+func (w *TargetsWriter) WriteAll(records [][]string) error {
+	for _, record := range records {
+		err := w.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return nil
+}
+
+func (w *TargetsWriter) Flush() {
+	for _, bw := range w.writers {
+		bw.Flush()
+	}
+}
+
+// Files paths of target files sorted by name
+func (w *TargetsWriter) Files() []string {
+	var files []string
+	for _, f := range w.files {
+		files = append(files, f.Name())
+	}
+	slices.Sort(files)
+	return files
+}
+
+// Close flush and close all target files
+func (w *TargetsWriter) Close() error {
+	var err error
+	for protocol, f := range w.files {
+		if e := w.writers[protocol].Flush(); e != nil && err == nil {
+			err = e
+		}
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// NewTargetsWriter generate targets writer, files are created in dir, or appended if appendOut is set
+// fields must contain host or ip, protocol is used to group
+func NewTargetsWriter(dir string, fields []string, appendOut bool) (*TargetsWriter, error) {
+	indexes := recordIndexes(fields, "host", "ip", "port", "protocol")
+	if indexes[0] == -1 && indexes[1] == -1 {
+		return nil, errors.New("targets format needs host or ip field")
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &TargetsWriter{
+		dir:       dir,
+		appendOut: appendOut,
+		indexes:   indexes,
+		files:     make(map[string]*os.File),
+		writers:   make(map[string]*bufio.Writer),
+		seen:      make(map[string]bool),
+	}, nil
+}
@@ -0,0 +1,97 @@
+package outformats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixURL(t *testing.T) {
+	assert.Equal(t, "https://a.com", FixURL("https://a.com", "http", ""))
+	assert.Equal(t, "https://a.com", FixURL("a.com", "https", ""))
+	assert.Equal(t, "redis://1.1.1.1:6379", FixURL("1.1.1.1:6379", "redis", ""))
+	assert.Equal(t, "http://1.1.1.1:22", FixURL("1.1.1.1:22", "ssh", ""))
+	assert.Equal(t, "ftp://1.1.1.1:21", FixURL("1.1.1.1:21", "ftp", "ftp://"))
+}
+
+func TestTargetsWriter(t *testing.T) {
+	dir := t.TempDir()
+	_, err := NewTargetsWriter(dir, []string{"port"}, false)
+	assert.Error(t, err)
+
+	w, err := NewTargetsWriter(dir, []string{"host", "ip", "port", "protocol"}, false)
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteAll([][]string{
+		{"a.com", "1.1.1.1", "80", "http"},
+		{"https://a.com", "1.1.1.1", "443", "https"},
+		{"a.com", "1.1.1.1", "80", "http"}, // duplicated
+		{"", "1.1.1.2", "22", "ssh"},
+		{"1.1.1.3:6379", "1.1.1.3", "6379", "redis"},
+		{"1.1.1.4:1", "1.1.1.4", "1", ""},
+		{"", "", "", "http"},
+	}))
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, "http_targets.txt"),
+		filepath.Join(dir, "https_targets.txt"),
+		filepath.Join(dir, "redis_targets.txt"),
+		filepath.Join(dir, "ssh_targets.txt"),
+		filepath.Join(dir, "unknown_targets.txt"),
+	}, w.Files())
+	for name, content := range map[string]string{
+		"http_targets.txt":    "http://a.com\n",
+		"https_targets.txt":   "https://a.com\n",
+		"redis_targets.txt":   "redis://1.1.1.3:6379\n",
+		"ssh_targets.txt":     "1.1.1.2:22\n",
+		"unknown_targets.txt": "1.1.1.4:1\n",
+	} {
+		d, err := os.ReadFile(filepath.Join(dir, name))
+		assert.Nil(t, err)
+		assert.Equal(t, content, string(d), name)
+	}
+}
+
+func TestTargetsWriter_Append(t *testing.T) {
+	dir := t.TempDir()
+	targetsDir := filepath.Join(dir, "targets")
+	fields := []string{"ip", "port", "protocol"}
+	assert.Nil(t, os.MkdirAll(targetsDir, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(targetsDir, "ssh_targets.txt"), []byte("1.1.1.9:22\n"), 0644))
+
+	// 每个查询一个新的写入器，第一个覆盖旧文件，之后追加
+	opened := false
+	w := NewDirWriter(filepath.Join(dir, "out"), ".txt", true, func(path string) (OutWriter, error) {
+		tw, err := NewTargetsWriter(targetsDir, fields, opened)
+		opened = true
+		return tw, err
+	})
+	w.SetMeta("q1", 3)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80", "http"}, {"1.1.1.2", "22", "ssh"}}))
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.3", "80", "http"}}))
+	w.SetMeta("q2", 3)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80", "http"}, {"1.1.1.4", "22", "ssh"}}))
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.5", "6379", "redis"}}))
+	assert.Nil(t, w.Close())
+
+	for name, content := range map[string]string{
+		"http_targets.txt":  "http://1.1.1.1:80\nhttp://1.1.1.3:80\n",
+		"ssh_targets.txt":   "1.1.1.2:22\n1.1.1.4:22\n",
+		"redis_targets.txt": "redis://1.1.1.5:6379\n",
+	} {
+		d, err := os.ReadFile(filepath.Join(targetsDir, name))
+		assert.Nil(t, err)
+		assert.Equal(t, content, string(d), name)
+	}
+	assert.ElementsMatch(t, []string{
+		filepath.Join(targetsDir, "http_targets.txt"),
+		filepath.Join(targetsDir, "ssh_targets.txt"),
+		filepath.Join(targetsDir, "http_targets.txt"),
+		filepath.Join(targetsDir, "redis_targets.txt"),
+		filepath.Join(targetsDir, "ssh_targets.txt"),
+	}, w.Files())
+	_, err := os.Stat(filepath.Join(dir, "out", "0001.txt"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,103 @@
+package outformats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"text/template"
+)
+
+// TemplateFuncs functions can be used in template, such as:
+// {{.ip}}:{{.port}}
+// {{split "," .certs_domains | join ";"}}
+// {{lower .title | urlencode}}
+var TemplateFuncs = template.FuncMap{
+	"join": func(sep string, elems []string) string {
+		return strings.Join(elems, sep)
+	},
+	"split": func(sep string, s string) []string {
+		return strings.Split(s, sep)
+	},
+	"lower":     strings.ToLower,
+	"urlencode": url.QueryEscape,
+	"json": func(v interface{}) (string, error) {
+		d, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(d), nil
+	},
+}
+
+// TemplateWriter go text/template format writer
+type TemplateWriter struct {
+	noHeader
+
+	fields []string
+	tmpl   *template.Template
+	w      *bufio.Writer
+	buf    bytes.Buffer
+}
+
+// Write renders a single record to w through the template,
+// each field name is the key of the record, a newline is appended if not ended with.
+// Writes are buffered, so Flush must eventually be called to ensure
+// that the record is written to the underlying io.Writer.
+func (w *TemplateWriter) Write(records []string) error {
+	if len(records) != len(w.fields) {
+		return errors.New("records length is not equal to fields")
+	}
+
+	m := make(map[string]string)
+	for i := range w.fields {
+		m[w.fields[i]] = records[i]
+	}
+
+	w.buf.Reset()
+	if err := w.tmpl.Execute(&w.buf, m); err != nil {
+		return err
+	}
+	if w.buf.Len() == 0 || w.buf.Bytes()[w.buf.Len()-1] != '\n' {
+		w.buf.WriteByte('\n')
+	}
+
+	_, err := w.w.Write(w.buf.Bytes())
+	return err
+}
+
+// WriteAll writes multiple records to w using Write and then calls Flush,
+// returning any error from the Flush.
+func (w *TemplateWriter) WriteAll(records [][]string) error {
+	for _, record := range records {
+		err := w.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	return w.w.Flush()
+}
+
+func (w *TemplateWriter) Flush() {
+	w.w.Flush()
+}
+
+// NewTemplateWriter generate template writer
+// fields are key field, text is the content of go text/template
+func NewTemplateWriter(w io.Writer, fields []string, text string) (*TemplateWriter, error) {
+	tmpl, err := template.New("record").
+		Option("missingkey=error").
+		Funcs(TemplateFuncs).
+		Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateWriter{
+		w:      bufio.NewWriter(w),
+		fields: fields,
+		tmpl:   tmpl,
+	}, nil
+}
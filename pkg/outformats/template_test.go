@@ -0,0 +1,42 @@
+package outformats
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateWriter(t *testing.T) {
+	var b bytes.Buffer
+	w, err := NewTemplateWriter(&b, []string{"ip", "port", "certs_domains"},
+		`{{.ip}}:{{.port}} {{split "," .certs_domains | join ";"}}`)
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80", "a.com,b.com"}, {"1.1.1.2", "443", ""}}))
+	assert.Equal(t, "1.1.1.1:80 a.com;b.com\n1.1.1.2:443 \n", b.String())
+
+	// newline is not appended twice, functions
+	b.Reset()
+	w, err = NewTemplateWriter(&b, []string{"title"}, "{{lower .title | urlencode}} {{json .title}}\n")
+	assert.Nil(t, err)
+	assert.Nil(t, w.WriteAll([][]string{{"Hello World"}}))
+	assert.Equal(t, "hello+world \"Hello World\"\n", b.String())
+
+	// records length
+	assert.Error(t, w.Write([]string{"a", "b"}))
+
+	// missing field
+	b.Reset()
+	w, err = NewTemplateWriter(&b, []string{"ip"}, "{{.ip}}:{{.port}}")
+	assert.Nil(t, err)
+	err = w.WriteAll([][]string{{"1.1.1.1"}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "port")
+	assert.Equal(t, "", b.String())
+
+	// invalid template
+	_, err = NewTemplateWriter(&b, []string{"ip"}, "{{.ip")
+	assert.Error(t, err)
+	_, err = NewTemplateWriter(&b, []string{"ip"}, "{{unknown .ip}}")
+	assert.Error(t, err)
+}
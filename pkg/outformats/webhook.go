@@ -0,0 +1,135 @@
+package outformats
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// WebhookSignatureHeader header of HMAC-SHA256 signature of request body, like sha256=<hex>
+const WebhookSignatureHeader = "X-Fofa-Signature-256"
+
+// webhookBatch body of each request
+type webhookBatch struct {
+	Query   string                   `json:"query,omitempty"`
+	Size    int                      `json:"size,omitempty"` // total size of query reported by server
+	Fields  []string                 `json:"fields"`
+	Results []map[string]interface{} `json:"results"`
+}
+
+// WebhookSink posts records to http endpoint as json batches like:
+// {"query":"port=80","size":100,"fields":["ip","port"],"results":[{"ip":"1.1.1.1","port":"80"}]}
+// requests are retried on network error, 429 and 5xx.
+// If Secret is set, the body is signed with HMAC-SHA256 in header X-Fofa-Signature-256.
+type WebhookSink struct {
+	noHeader
+
+	URL       string
+	Header    http.Header
+	Secret    string
+	Retry     int           // retry count
+	RetryWait time.Duration // wait before retry, increased by attempts
+	BatchSize int           // max records of each request
+
+	fields     []string
+	typed      bool
+	query      string
+	size       int
+	httpClient *http.Client
+}
+
+// Sign HMAC-SHA256 signature of body with secret, like sha256=<hex>
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *WebhookSink) post(body []byte) (retryable bool, err error) {
+	req, err := http.NewRequest("POST", s.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for k, v := range s.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.Secret) > 0 {
+		req.Header.Set(WebhookSignatureHeader, Sign(s.Secret, body))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return true, fmt.Errorf("webhook request failed: %s", resp.Status)
+	}
+	if resp.StatusCode >= 300 {
+		return false, fmt.Errorf("webhook request failed: %s, %s", resp.Status, data)
+	}
+	return false, nil
+}
+
+func (s *WebhookSink) send(records [][]string) error {
+	batch := webhookBatch{
+		Query:  s.query,
+		Size:   s.size,
+		Fields: s.fields,
+	}
+	for _, record := range records {
+		batch.Results = append(batch.Results, recordValues(s.fields, record, s.typed))
+	}
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	return retry(s.Retry, s.RetryWait, func() (bool, error) {
+		return s.post(body)
+	})
+}
+
+// WriteAll posts records as batches
+func (s *WebhookSink) WriteAll(records [][]string) error {
+	return eachBatch(records, s.BatchSize, s.send)
+}
+
+func (s *WebhookSink) Flush() {
+}
+
+// SetMeta set the query of following batches
+func (s *WebhookSink) SetMeta(query string, size int) {
+	s.query = query
+	s.size = size
+}
+
+// NewWebhookSink generate webhook sink of http or https url,
+// values are typed by FieldTypes if typed
+func NewWebhookSink(sinkURL string, fields []string, typed bool) (*WebhookSink, error) {
+	u, err := url.Parse(sinkURL)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid webhook sink: %s", sinkURL)
+	}
+	return &WebhookSink{
+		URL:        sinkURL,
+		Header:     make(http.Header),
+		Retry:      3,
+		RetryWait:  time.Second,
+		BatchSize:  100,
+		fields:     fields,
+		typed:      typed,
+		httpClient: &http.Client{Timeout: time.Minute},
+	}, nil
+}
@@ -0,0 +1,67 @@
+package outformats
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSink(t *testing.T) {
+	var requests int32
+	var batches []webhookBatch
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		d, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer abc", r.Header.Get("Authorization"))
+		assert.Equal(t, Sign("secret", d), r.Header.Get(WebhookSignatureHeader))
+		switch {
+		case n == 1:
+			// first request fails, retried
+			w.WriteHeader(http.StatusTooManyRequests)
+		case r.URL.Path == "/bad":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			var b webhookBatch
+			assert.Nil(t, json.Unmarshal(d, &b))
+			batches = append(batches, b)
+		}
+	}))
+	defer ts.Close()
+
+	_, err := NewWebhookSink("ftp://127.0.0.1/hook", []string{"ip"}, false)
+	assert.Error(t, err)
+
+	s, err := NewWebhookSink(ts.URL+"/hook", []string{"ip", "port"}, true)
+	assert.Nil(t, err)
+	s.Header.Set("Authorization", "Bearer abc")
+	s.Secret = "secret"
+	s.RetryWait = time.Millisecond
+	s.BatchSize = 2
+	s.SetMeta("port=80", 100)
+
+	assert.Nil(t, s.WriteAll([][]string{{"1.1.1.1", "80"}, {"1.1.1.2", "80"}, {"1.1.1.3", "80"}}))
+	assert.Equal(t, int32(3), requests)
+	assert.Equal(t, 2, len(batches))
+	assert.Equal(t, "port=80", batches[0].Query)
+	assert.Equal(t, 100, batches[0].Size)
+	assert.Equal(t, []string{"ip", "port"}, batches[0].Fields)
+	assert.Equal(t, 2, len(batches[0].Results))
+	assert.Equal(t, float64(80), batches[1].Results[0]["port"])
+
+	// client errors are not retried
+	s.URL = ts.URL + "/bad"
+	assert.Error(t, s.WriteAll([][]string{{"1.1.1.1", "80"}}))
+	assert.Equal(t, int32(4), requests)
+}
+
+func TestSign(t *testing.T) {
+	assert.Equal(t, "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		Sign("key", []byte("The quick brown fox jumps over the lazy dog")))
+}
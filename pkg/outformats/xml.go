@@ -5,43 +5,154 @@ import (
 	"encoding/xml"
 	"errors"
 	"io"
+	"strconv"
+	"strings"
 )
 
-// XMLWriter XML format writer
+const (
+	xmlRootName   = "results"
+	xmlRecordName = "result"
+)
+
+// XMLWriter XML format writer, writes a document like:
+// <results><result><ip>1.1.1.1</ip><port>80</port></result></results>
+// or in attributes mode:
+// <results><result ip="1.1.1.1" port="80"></result></results>
 type XMLWriter struct {
-	fields []string
-	w      *bufio.Writer
+	noHeader
+
+	fields  []string
+	names   []xml.Name // escaped xml names of fields
+	attrs   bool       // fields as attributes of result
+	w       *bufio.Writer
+	e       *xml.Encoder
+	started bool // the root element is written
 }
 
-// Write writes a single JSON record to w one line.
-// A record is a slice of strings with each string being one field.
-// Writes are buffered, so Flush must eventually be called to ensure
-// that the record is written to the underlying io.Writer.
-func (w *XMLWriter) Write(records []string) error {
-	if len(records) != len(w.fields) {
-		return errors.New("records length is not equal to fields")
+// xmlName escape field to a valid xml name, invalid characters are replaced by _
+func xmlName(field string) string {
+	var b strings.Builder
+	for i, r := range field {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case r >= '0' && r <= '9', r == '-':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+		default:
+			r = '_'
+		}
+		b.WriteRune(r)
 	}
+	name := b.String()
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		name = "_" + name
+	}
+	return name
+}
 
-	m := make(result)
-	for i := range w.fields {
-		m[w.fields[i]] = records[i]
+// xmlNames escape fields in order, duplicated names are suffixed by index
+func xmlNames(fields []string) []xml.Name {
+	var names []xml.Name
+	seen := make(map[string]bool)
+	for i, field := range fields {
+		name := xmlName(field)
+		if seen[name] {
+			name = name + "_" + strconv.Itoa(i)
+		}
+		seen[name] = true
+		names = append(names, xml.Name{Local: name})
 	}
-	d, err := xml.Marshal(m)
-	if err != nil {
-		return err
+	return names
+}
+
+// XMLSchema xsd of the document written by XMLWriter of fields,
+// each result has all fields in order as child elements, or as attributes if attrs is true
+func XMLSchema(fields []string, attrs bool) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" elementFormDefault="unqualified">
+  <xs:element name="` + xmlRootName + `">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="` + xmlRecordName + `" minOccurs="0" maxOccurs="unbounded">
+          <xs:complexType>
+`)
+	names := xmlNames(fields)
+	if attrs {
+		for _, name := range names {
+			b.WriteString(`            <xs:attribute name="` + name.Local + `" type="xs:string" use="required"/>` + "\n")
+		}
+	} else {
+		b.WriteString("            <xs:sequence>\n")
+		for _, name := range names {
+			b.WriteString(`              <xs:element name="` + name.Local + `" type="xs:string"/>` + "\n")
+		}
+		b.WriteString("            </xs:sequence>\n")
 	}
+	b.WriteString(`          </xs:complexType>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>
+`)
+	return b.String()
+}
 
-	if _, err := w.w.Write(d); err != nil {
+// Schema xsd of the document written by w
+func (w *XMLWriter) Schema() string {
+	return XMLSchema(w.fields, w.attrs)
+}
+
+func (w *XMLWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	if _, err := w.w.WriteString(xml.Header); err != nil {
 		return err
 	}
-	if _, err := w.w.WriteString("\n"); err != nil {
+	return w.e.EncodeToken(xml.StartElement{Name: xml.Name{Local: xmlRootName}})
+}
+
+// Write writes a single XML record to w as a result element,
+// the order of fields is kept.
+// Writes are buffered, so Close must eventually be called to ensure
+// that the document is ended and written to the underlying io.Writer.
+func (w *XMLWriter) Write(records []string) error {
+	if len(records) != len(w.fields) {
+		return errors.New("records length is not equal to fields")
+	}
+	if err := w.start(); err != nil {
 		return err
 	}
 
+	start := xml.StartElement{Name: xml.Name{Local: xmlRecordName}}
+	tokens := []xml.Token{}
+	if w.attrs {
+		for i := range w.fields {
+			start.Attr = append(start.Attr, xml.Attr{Name: w.names[i], Value: records[i]})
+		}
+		tokens = append(tokens, start)
+	} else {
+		tokens = append(tokens, start)
+		for i := range w.fields {
+			t := xml.StartElement{Name: w.names[i]}
+			tokens = append(tokens, t, xml.CharData(records[i]), t.End())
+		}
+	}
+	tokens = append(tokens, start.End())
+
+	for _, t := range tokens {
+		if err := w.e.EncodeToken(t); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// WriteAll writes multiple json records to w using Write and then calls Flush,
+// WriteAll writes multiple xml records to w using Write and then calls Flush,
 // returning any error from the Flush.
 func (w *XMLWriter) WriteAll(records [][]string) error {
 	for _, record := range records {
@@ -50,44 +161,55 @@ func (w *XMLWriter) WriteAll(records [][]string) error {
 			return err
 		}
 	}
+	if err := w.e.Flush(); err != nil {
+		return err
+	}
 	return w.w.Flush()
 }
 
 func (w *XMLWriter) Flush() {
+	w.e.Flush()
 	w.w.Flush()
 }
 
-// StringMap is a map[string]string.
-type result map[string]string
-
-// StringMap marshals into XML.
-func (s result) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
-
-	tokens := []xml.Token{start}
-
-	for key, value := range s {
-		t := xml.StartElement{Name: xml.Name{"", key}}
-		tokens = append(tokens, t, xml.CharData(value), xml.EndElement{t.Name})
+// Close writes the end of root element
+func (w *XMLWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
 	}
-
-	tokens = append(tokens, xml.EndElement{start.Name})
-
-	for _, t := range tokens {
-		err := e.EncodeToken(t)
-		if err != nil {
-			return err
-		}
+	if err := w.e.EncodeToken(xml.EndElement{Name: xml.Name{Local: xmlRootName}}); err != nil {
+		return err
 	}
-
-	// flush to ensure tokens are written
-	return e.Flush()
+	if err := w.e.Flush(); err != nil {
+		return err
+	}
+	if _, err := w.w.WriteString("\n"); err != nil {
+		return err
+	}
+	return w.w.Flush()
 }
 
-// NewXMLWriter generate xml writer
-// fields are key field
-func NewXMLWriter(w io.Writer, fields []string) *XMLWriter {
+func newXMLWriter(w io.Writer, fields []string, attrs bool) *XMLWriter {
+	bw := bufio.NewWriter(w)
+	e := xml.NewEncoder(bw)
+	e.Indent("", "  ")
 	return &XMLWriter{
-		w:      bufio.NewWriter(w),
+		w:      bw,
+		e:      e,
 		fields: fields,
+		names:  xmlNames(fields),
+		attrs:  attrs,
 	}
 }
+
+// NewXMLWriter generate xml writer
+// fields are key field, each field is a child element of result
+func NewXMLWriter(w io.Writer, fields []string) *XMLWriter {
+	return newXMLWriter(w, fields, false)
+}
+
+// NewXMLAttrWriter generate xml writer
+// fields are key field, each field is an attribute of result
+func NewXMLAttrWriter(w io.Writer, fields []string) *XMLWriter {
+	return newXMLWriter(w, fields, true)
+}
@@ -0,0 +1,123 @@
+package outformats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// xmlElements names of start elements and attributes of a well-formed xml document
+func xmlElements(t *testing.T, data []byte) []string {
+	var names []string
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if !assert.Nil(t, err) {
+			break
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			names = append(names, start.Name.Local)
+			for _, attr := range start.Attr {
+				names = append(names, "@"+attr.Name.Local)
+			}
+		}
+	}
+	return names
+}
+
+func TestXMLName(t *testing.T) {
+	assert.Equal(t, "ip", xmlName("ip"))
+	assert.Equal(t, "cert_subject", xmlName("cert.subject"))
+	assert.Equal(t, "_1abc", xmlName("1abc"))
+	assert.Equal(t, "a-b_c", xmlName("a-b c"))
+	assert.Equal(t, "_xmlns", xmlName("xmlns"))
+	assert.Equal(t, "_", xmlName(""))
+	assert.Equal(t, []xml.Name{{Local: "a_b"}, {Local: "a_b_1"}}, xmlNames([]string{"a.b", "a b"}))
+}
+
+func TestXMLWriter(t *testing.T) {
+	fields := []string{"ip", "port", "cert.subject"}
+	var b bytes.Buffer
+	w := NewXMLWriter(&b, fields)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80", "<a&b>"}}))
+	assert.Nil(t, w.Write([]string{"1.1.1.2", "443", ""}))
+	assert.Error(t, w.Write([]string{"1.1.1.3"}))
+	assert.Nil(t, w.Close())
+	assert.Equal(t, xml.Header+`<results>
+  <result>
+    <ip>1.1.1.1</ip>
+    <port>80</port>
+    <cert_subject>&lt;a&amp;b&gt;</cert_subject>
+  </result>
+  <result>
+    <ip>1.1.1.2</ip>
+    <port>443</port>
+    <cert_subject></cert_subject>
+  </result>
+</results>
+`, b.String())
+	assert.Equal(t, []string{"results", "result", "ip", "port", "cert_subject", "result", "ip", "port", "cert_subject"},
+		xmlElements(t, b.Bytes()))
+
+	// attributes
+	b.Reset()
+	w = NewXMLAttrWriter(&b, fields)
+	assert.Nil(t, w.WriteAll([][]string{{"1.1.1.1", "80", `"a"`}}))
+	assert.Nil(t, w.Close())
+	assert.Equal(t, []string{"results", "result", "@ip", "@port", "@cert_subject"}, xmlElements(t, b.Bytes()))
+	assert.Contains(t, b.String(), `<result ip="1.1.1.1" port="80" cert_subject="&#34;a&#34;"></result>`)
+
+	// no records
+	b.Reset()
+	w = NewXMLWriter(&b, fields)
+	assert.Nil(t, w.Close())
+	assert.Equal(t, []string{"results"}, xmlElements(t, b.Bytes()))
+}
+
+func TestXMLSchema(t *testing.T) {
+	fields := []string{"ip", "port", "cert.subject"}
+
+	// fields are declared in order as elements or attributes of result
+	var schema struct {
+		Element struct {
+			Name   string `xml:"name,attr"`
+			Result struct {
+				Name     string `xml:"name,attr"`
+				Elements []struct {
+					Name string `xml:"name,attr"`
+				} `xml:"complexType>sequence>element"`
+				Attributes []struct {
+					Name string `xml:"name,attr"`
+					Use  string `xml:"use,attr"`
+				} `xml:"complexType>attribute"`
+			} `xml:"complexType>sequence>element"`
+		} `xml:"element"`
+	}
+	w := NewXMLWriter(io.Discard, fields)
+	assert.Nil(t, xml.Unmarshal([]byte(w.Schema()), &schema))
+	assert.Equal(t, "results", schema.Element.Name)
+	assert.Equal(t, "result", schema.Element.Result.Name)
+	var names []string
+	for _, e := range schema.Element.Result.Elements {
+		names = append(names, e.Name)
+	}
+	assert.Equal(t, []string{"ip", "port", "cert_subject"}, names)
+	assert.Empty(t, schema.Element.Result.Attributes)
+
+	schema.Element.Result.Elements = nil
+	w = NewXMLAttrWriter(io.Discard, fields)
+	assert.Nil(t, xml.Unmarshal([]byte(w.Schema()), &schema))
+	names = nil
+	for _, a := range schema.Element.Result.Attributes {
+		names = append(names, a.Name)
+		assert.Equal(t, "required", a.Use)
+	}
+	assert.Equal(t, []string{"ip", "port", "cert_subject"}, names)
+	assert.Empty(t, schema.Element.Result.Elements)
+}
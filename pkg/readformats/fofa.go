@@ -0,0 +1,151 @@
+package readformats
+
+import (
+	"strings"
+
+	"github.com/FofaInfo/GoFOFA/pkg/outformats"
+)
+
+// fofaFields fields of fofa api
+var fofaFields = []string{
+	"ip", "port", "protocol", "country", "country_name", "region", "city", "longitude", "latitude",
+	"asn", "as_number", "as_organization", "org", "host", "domain", "os", "server", "icp", "title", "jarm",
+	"header", "banner", "cert", "base_protocol", "link", "url", "product", "product_category", "version",
+	"lastupdatetime", "cname", "cname_domain", "icon_hash", "certs_valid", "certs_domains",
+	"certs_issuer_org", "certs_issuer_cn", "certs_subject_org", "certs_subject_cn", "certs_match_key",
+	"certs_not_after", "certs_not_before", "tls_ja3s", "tls_version", "header_hash", "banner_hash",
+	"body_hash", "body", "icon", "fid", "structinfo", "status_code", "type", "isActive",
+}
+
+// webExportNames display names of columns in files exported from fofa website, in lower case
+var webExportNames = map[string]string{
+	"ip地址":             "ip",
+	"ip address":       "ip",
+	"端口":               "port",
+	"协议":               "protocol",
+	"传输层协议":            "base_protocol",
+	"base protocol":    "base_protocol",
+	"国家":               "country_name",
+	"国家代码":             "country",
+	"country code":     "country",
+	"地区":               "region",
+	"省份":               "region",
+	"城市":               "city",
+	"经度":               "longitude",
+	"纬度":               "latitude",
+	"asn编号":            "as_number",
+	"as number":        "as_number",
+	"asn组织":            "as_organization",
+	"组织":               "as_organization",
+	"organization":     "as_organization",
+	"as organization":  "as_organization",
+	"主机":               "host",
+	"网站":               "host",
+	"域名":               "domain",
+	"操作系统":             "os",
+	"服务器":              "server",
+	"备案号":              "icp",
+	"icp备案":            "icp",
+	"标题":               "title",
+	"网站标题":             "title",
+	"响应头":              "header",
+	"协议响应":             "banner",
+	"证书":               "cert",
+	"证书信息":             "cert",
+	"链接":               "link",
+	"产品":               "product",
+	"组件":               "product",
+	"产品分类":             "product_category",
+	"版本":               "version",
+	"更新时间":             "lastupdatetime",
+	"最后更新时间":           "lastupdatetime",
+	"last update time": "lastupdatetime",
+	"update time":      "lastupdatetime",
+	"图标hash":           "icon_hash",
+	"icon hash":        "icon_hash",
+	"状态码":              "status_code",
+	"status code":      "status_code",
+}
+
+func isFofaField(name string) bool {
+	for _, field := range fofaFields {
+		if field == name {
+			return true
+		}
+	}
+	_, ok := outformats.FieldTypes[name]
+	return ok
+}
+
+// webExportField fofa field of display name, empty if unknown
+func webExportField(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if field, ok := webExportNames[name]; ok {
+		return field
+	}
+	if isFofaField(name) {
+		// english display name like IP, Port, Title
+		return name
+	}
+	return ""
+}
+
+// WebExportFields map display names of fofa web export to fofa fields, unknown names are kept
+func WebExportFields(header []string) []string {
+	fields := make([]string, len(header))
+	for i, name := range header {
+		if field := webExportField(name); field != "" {
+			fields[i] = field
+		} else {
+			fields[i] = name
+		}
+	}
+	return fields
+}
+
+// knownColumns count of columns which are fofa fields or display names
+func knownColumns(row []string) int {
+	n := 0
+	for _, name := range row {
+		if webExportField(name) != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// isTitleRow row has at most one value, like the query line before header
+func isTitleRow(row []string) bool {
+	n := 0
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			n++
+		}
+	}
+	return n <= 1
+}
+
+// isWebExportHeader row has at least two display names which are not fofa fields, like IP地址, 端口
+func isWebExportHeader(row []string) bool {
+	n := 0
+	for _, name := range row {
+		if !isFofaField(name) && webExportField(name) != "" {
+			n++
+		}
+	}
+	return n >= 2
+}
+
+// IsHeader row looks like a header: it has one of names, or a fofa field or display name.
+// It is used to tell whether the first row of a csv is a header or a value, like a headerless list of hosts.
+func IsHeader(row []string, names ...string) bool {
+	for _, name := range row {
+		name = strings.TrimSpace(name)
+		for _, n := range names {
+			if len(n) > 0 && name == n {
+				return true
+			}
+		}
+	}
+	return knownColumns(row) > 0
+}
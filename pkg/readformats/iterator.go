@@ -0,0 +1,116 @@
+package readformats
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// RowError a row which can't be aligned with the header, only the row is skipped
+type RowError struct {
+	Line int // line number of the row, row number of xlsx
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Iterator iterates records of a Reader one at a time like bufio.Scanner,
+// so memory stays constant however large the file is.
+// Rows of RowError are skipped and reported to OnSkip:
+//
+//	rows, err := Iterate("data.csv", "")
+//	...
+//	defer rows.Close()
+//	for rows.Next() {
+//		row := rows.Row()
+//	}
+//	err = rows.Err()
+type Iterator struct {
+	OnSkip func(err *RowError) // called with each skipped row if not nil
+
+	r       Reader
+	record  []string
+	count   int
+	skipped int
+	err     error
+}
+
+// NewIterator iterator of records of r
+func NewIterator(r Reader) *Iterator {
+	return &Iterator{r: r}
+}
+
+// Iterate open file as iterator, format is detected if empty
+func Iterate(path string, format string) (*Iterator, error) {
+	r, err := Open(path, format)
+	if err != nil {
+		return nil, err
+	}
+	return NewIterator(r), nil
+}
+
+// Fields header of the file
+func (it *Iterator) Fields() []string {
+	return it.r.Fields()
+}
+
+// Next advance to the next record, false at the end or on error
+func (it *Iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	it.record, it.err = it.r.Read()
+	var rowErr *RowError
+	for errors.As(it.err, &rowErr) {
+		it.skipped++
+		if it.OnSkip != nil {
+			it.OnSkip(rowErr)
+		}
+		it.record, it.err = it.r.Read()
+	}
+	if it.err != nil {
+		it.record = nil
+		return false
+	}
+	it.count++
+	return true
+}
+
+// Record current record aligned with Fields, only valid until the next call of Next
+func (it *Iterator) Record() []string {
+	return it.record
+}
+
+// Row current record as field map
+func (it *Iterator) Row() CSVRow {
+	return ToRow(it.r.Fields(), it.record)
+}
+
+// Count records read so far
+func (it *Iterator) Count() int {
+	return it.count
+}
+
+// Skipped count of rows skipped for RowError
+func (it *Iterator) Skipped() int {
+	return it.skipped
+}
+
+// Err the first error of iteration, nil at the normal end
+func (it *Iterator) Err() error {
+	if it.err == io.EOF {
+		return nil
+	}
+	return it.err
+}
+
+// Close the reader
+func (it *Iterator) Close() error {
+	return it.r.Close()
+}
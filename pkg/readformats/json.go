@@ -0,0 +1,142 @@
+package readformats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/FofaInfo/GoFOFA/pkg/outformats"
+)
+
+// jsonReader reader of json lines, as written by outformats.JSONWriter,
+// fields are keys of the first object, typed values are converted back to text like fofa api
+type jsonReader struct {
+	r       *bufio.Reader
+	fields  []string
+	line    int
+	pending []byte // first line
+}
+
+// nextLine next non-empty line, io.EOF at the end
+func (r *jsonReader) nextLine() ([]byte, error) {
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			r.line++
+			return line, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		r.line++
+	}
+}
+
+// objectKeys keys of json object in order
+func objectKeys(line []byte) ([]string, error) {
+	d := json.NewDecoder(bytes.NewReader(line))
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	if t != json.Delim('{') {
+		return nil, errors.New("json line is not an object")
+	}
+	var keys []string
+	for d.More() {
+		t, err = d.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, t.(string))
+		var v json.RawMessage
+		if err = d.Decode(&v); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// textValue text of json value, lists are joined by comma, times are formatted as fofa time
+func textValue(field string, v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		if outformats.TypeOfField(field) == outformats.FieldTypeTime {
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				return t.In(outformats.FofaTimeLocation).Format(outformats.FofaTimeLayout)
+			}
+		}
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			values = append(values, textValue("", item))
+		}
+		return strings.Join(values, ",")
+	default:
+		d, _ := json.Marshal(v)
+		return string(d)
+	}
+}
+
+func (r *jsonReader) Fields() []string {
+	return r.fields
+}
+
+// Read next json line
+func (r *jsonReader) Read() ([]string, error) {
+	line := r.pending
+	r.pending = nil
+	if line == nil {
+		var err error
+		if line, err = r.nextLine(); err != nil {
+			return nil, err
+		}
+	}
+
+	d := json.NewDecoder(bytes.NewReader(line))
+	d.UseNumber()
+	var m map[string]interface{}
+	if err := d.Decode(&m); err != nil {
+		return nil, fmt.Errorf("parse json of line %d failed: %w", r.line, err)
+	}
+	record := make([]string, len(r.fields))
+	for i, field := range r.fields {
+		record[i] = textValue(field, m[field])
+	}
+	return record, nil
+}
+
+func (r *jsonReader) Close() error {
+	return nil
+}
+
+func newJSONReader(br *bufio.Reader) (*jsonReader, error) {
+	r := &jsonReader{r: br}
+	line, err := r.nextLine()
+	if err == io.EOF {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.fields, err = objectKeys(line); err != nil {
+		return nil, fmt.Errorf("parse json of line %d failed: %w", r.line, err)
+	}
+	r.pending = line
+	return r, nil
+}
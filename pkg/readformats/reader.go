@@ -0,0 +1,179 @@
+package readformats
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// formats of input files
+const (
+	FormatCSV  = "csv"
+	FormatTSV  = "tsv"
+	FormatJSON = "json" // json lines, as written by outformats.JSONWriter
+	FormatXML  = "xml"  // as written by outformats.XMLWriter
+	FormatXLSX = "xlsx"
+	FormatFOFA = "fofa" // csv or xlsx exported from fofa website
+)
+
+// Formats formats which can be read
+var Formats = []string{FormatCSV, FormatTSV, FormatJSON, FormatXML, FormatXLSX, FormatFOFA}
+
+// extFormats format of file extension
+var extFormats = map[string]string{
+	".csv":    FormatCSV,
+	".tsv":    FormatTSV,
+	".json":   FormatJSON,
+	".jsonl":  FormatJSON,
+	".ndjson": FormatJSON,
+	".xml":    FormatXML,
+	".xlsx":   FormatXLSX,
+}
+
+// Reader reads records of input file, each record is aligned with Fields
+type Reader interface {
+	Fields() []string
+	Read() ([]string, error) // next record, io.EOF at the end
+	Close() error
+}
+
+var utf8BOM = []byte("\xEF\xBB\xBF")
+
+// sniff format by the beginning of content, empty if unknown
+func sniff(head []byte) string {
+	if bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+		return FormatXLSX
+	}
+	head = bytes.TrimLeft(bytes.TrimPrefix(head, utf8BOM), " \t\r\n")
+	switch {
+	case bytes.HasPrefix(head, []byte("{")):
+		return FormatJSON
+	case bytes.HasPrefix(head, []byte("<")):
+		return FormatXML
+	}
+	return ""
+}
+
+// DetectFormat format of file by extension, or by content if extension is unknown,
+// empty if not detected, like plain text
+func DetectFormat(path string) (string, error) {
+	if format, ok := extFormats[strings.ToLower(filepath.Ext(path))]; ok {
+		return format, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	return sniff(head[:n]), nil
+}
+
+// Open reader of file, format is detected by DetectFormat if empty, and csv if not detected
+func Open(path string, format string) (Reader, error) {
+	if len(format) == 0 {
+		var err error
+		if format, err = DetectFormat(path); err != nil {
+			return nil, err
+		}
+		if len(format) == 0 {
+			format = FormatCSV
+		}
+	}
+
+	if format == FormatXLSX || (format == FormatFOFA && isXLSX(path)) {
+		rows, err := openXLSX(path)
+		if err != nil {
+			return nil, fmt.Errorf("open xlsx %s failed: %w", path, err)
+		}
+		return newTableReader(rows, rows, format == FormatFOFA)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(f, format)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &fileReader{Reader: r, f: f}, nil
+}
+
+// NewReader reader of format from r, xlsx needs random access so it can only be opened by Open
+func NewReader(r io.Reader, format string) (Reader, error) {
+	br := bufio.NewReader(r)
+	if head, _ := br.Peek(len(utf8BOM)); bytes.Equal(head, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+
+	switch format {
+	case FormatCSV, FormatFOFA:
+		return newTableReader(newCSVRows(br, ','), nil, format == FormatFOFA)
+	case FormatTSV:
+		return newTableReader(newCSVRows(br, '\t'), nil, false)
+	case FormatJSON:
+		return newJSONReader(br)
+	case FormatXML:
+		return newXMLReader(br)
+	case FormatXLSX:
+		return nil, fmt.Errorf("format %s can only be read from file", format)
+	default:
+		return nil, fmt.Errorf("unknown input format: %s", format)
+	}
+}
+
+// fileReader closes the file with reader
+type fileReader struct {
+	Reader
+	f *os.File
+}
+
+func (r *fileReader) Close() error {
+	err := r.Reader.Close()
+	if e := r.f.Close(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
+
+// ToRow map record to fields
+func ToRow(fields []string, record []string) CSVRow {
+	row := make(CSVRow, len(fields))
+	for i, field := range fields {
+		if i < len(record) {
+			row[field] = record[i]
+		} else {
+			row[field] = ""
+		}
+	}
+	return row
+}
+
+// LoadFile load all records of file as rows, format is detected if empty.
+// Rows are kept in memory, use Iterate for large files.
+func LoadFile(path string, format string) ([]CSVRow, []string, error) {
+	rows, err := Iterate(path, format)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var data []CSVRow
+	for rows.Next() {
+		data = append(data, rows.Row())
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return data, rows.Fields(), nil
+}
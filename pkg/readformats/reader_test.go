@@ -0,0 +1,258 @@
+package readformats
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FofaInfo/GoFOFA/pkg/outformats"
+	"github.com/stretchr/testify/assert"
+)
+
+func readAll(t *testing.T, r Reader) [][]string {
+	var records [][]string
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		if err != nil {
+			break
+		}
+		records = append(records, record)
+	}
+	assert.Nil(t, r.Close())
+	return records
+}
+
+func writeFile(t *testing.T, name string, data string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, os.WriteFile(path, []byte(data), 0644))
+	return path
+}
+
+// writeXLSX write minimal xlsx with shared strings and inline strings
+func writeXLSX(t *testing.T, sheet string, shared string) string {
+	path := filepath.Join(t.TempDir(), "a.xlsx")
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+	w := zip.NewWriter(f)
+	files := map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="data" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/data.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` + shared + `</sst>`,
+		"xl/worksheets/data.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheet + `</sheetData></worksheet>`,
+	}
+	for name, data := range files {
+		fw, err := w.Create(name)
+		assert.Nil(t, err)
+		_, err = fw.Write([]byte(data))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, w.Close())
+	assert.Nil(t, f.Close())
+	return path
+}
+
+func TestOpen_CSV(t *testing.T) {
+	r, err := Open(writeFile(t, "a.csv", "\xEF\xBB\xBFip,port,title\n1.1.1.1,80,\"a,b\"\n1.1.1.2,443\n"), "")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ip", "port", "title"}, r.Fields())
+	assert.Equal(t, [][]string{{"1.1.1.1", "80", "a,b"}, {"1.1.1.2", "443", ""}}, readAll(t, r))
+
+	r, err = Open(writeFile(t, "a.tsv", "ip\tport\n1.1.1.1\t80\n"), "")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ip", "port"}, r.Fields())
+	assert.Equal(t, [][]string{{"1.1.1.1", "80"}}, readAll(t, r))
+
+	// ragged rows, trailing empty values are dropped, header is trimmed
+	r, err = Open(writeFile(t, "a.csv", "ip, port\n1.1.1.1,80,,\n1.1.1.2\n"), "")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ip", "port"}, r.Fields())
+	assert.Equal(t, [][]string{{"1.1.1.1", "80"}, {"1.1.1.2", ""}}, readAll(t, r))
+
+	// too many fields, reported with line number, the next row can be read
+	r, err = Open(writeFile(t, "a.csv", "ip\n1.1.1.1\n\"a\nb\"\n1.1.1.2,80\n1.1.1.3\n"), "")
+	assert.Nil(t, err)
+	_, err = r.Read()
+	assert.Nil(t, err)
+	_, err = r.Read()
+	assert.Nil(t, err)
+	_, err = r.Read()
+	var rowErr *RowError
+	assert.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, 5, rowErr.Line)
+	assert.ErrorContains(t, err, "line 5")
+	record, err := r.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.1.1.3"}, record)
+}
+
+func TestOpen_WebExport(t *testing.T) {
+	r, err := Open(writeFile(t, "export.csv", "查询语句: app=\"Redis\"\nIP地址,端口,网站标题,Host,备注\n1.1.1.1,6379,,1.1.1.1:6379,x\n"), "")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ip", "port", "title", "host", "备注"}, r.Fields())
+	assert.Equal(t, [][]string{{"1.1.1.1", "6379", "", "1.1.1.1:6379", "x"}}, readAll(t, r))
+
+	r, err = Open(writeFile(t, "export.csv", "IP,Port\n1.1.1.1,80\n"), FormatFOFA)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ip", "port"}, r.Fields())
+	assert.Equal(t, [][]string{{"1.1.1.1", "80"}}, readAll(t, r))
+
+	// single column without header like
+	r, err = Open(writeFile(t, "a.csv", "name\na\nb\n"), "")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"name"}, r.Fields())
+	assert.Equal(t, [][]string{{"a"}, {"b"}}, readAll(t, r))
+}
+
+func TestIsHeader(t *testing.T) {
+	// headerless one column csv, the first row is a value
+	r, err := Open(writeFile(t, "hosts.csv", "1.1.1.1\nexample.com\n"), "")
+	assert.Nil(t, err)
+	assert.False(t, IsHeader(r.Fields(), "", "ip", "host"))
+	assert.Nil(t, r.Close())
+
+	r, err = Open(writeFile(t, "hosts.csv", "host\n1.1.1.1\nexample.com\n"), "")
+	assert.Nil(t, err)
+	assert.True(t, IsHeader(r.Fields()))
+	assert.Equal(t, [][]string{{"1.1.1.1"}, {"example.com"}}, readAll(t, r))
+
+	assert.True(t, IsHeader([]string{" query "}, "query"))
+	assert.True(t, IsHeader([]string{"name", "IP地址"}))
+	assert.False(t, IsHeader([]string{"port=80"}, "query"))
+}
+
+func TestOpen_JSON(t *testing.T) {
+	fields := []string{"ip", "port", "certs_domains", "lastupdatetime"}
+	var b bytes.Buffer
+	w := outformats.NewTypedJSONWriter(&b, fields)
+	assert.Nil(t, w.WriteAll([][]string{
+		{"1.1.1.1", "80", "a.com,b.com", "2024-01-10 10:00:00"},
+		{"1.1.1.2", "", "", ""},
+	}))
+
+	path := writeFile(t, "a.data", "\n"+b.String())
+	format, err := DetectFormat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, FormatJSON, format)
+
+	r, err := Open(path, "")
+	assert.Nil(t, err)
+	// keys are sorted by json writer
+	assert.Equal(t, []string{"certs_domains", "ip", "lastupdatetime", "port"}, r.Fields())
+	assert.Equal(t, [][]string{
+		{"a.com,b.com", "1.1.1.1", "2024-01-10 10:00:00", "80"},
+		{"", "1.1.1.2", "", ""},
+	}, readAll(t, r))
+
+	r, err = NewReader(strings.NewReader(`{"ip":"1.1.1.1"}`+"\n[1]\n"), FormatJSON)
+	assert.Nil(t, err)
+	_, err = r.Read()
+	assert.Nil(t, err)
+	_, err = r.Read()
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestOpen_XML(t *testing.T) {
+	fields := []string{"ip", "cert.subject"}
+	records := [][]string{{"1.1.1.1", "CN=a"}, {"1.1.1.2", "<b>"}}
+	for _, attrs := range []bool{false, true} {
+		var b bytes.Buffer
+		var w *outformats.XMLWriter
+		if attrs {
+			w = outformats.NewXMLAttrWriter(&b, fields)
+		} else {
+			w = outformats.NewXMLWriter(&b, fields)
+		}
+		assert.Nil(t, w.WriteAll(records))
+		assert.Nil(t, w.Close())
+
+		r, err := Open(writeFile(t, "a.xml", b.String()), "")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"ip", "cert_subject"}, r.Fields())
+		assert.Equal(t, records, readAll(t, r))
+	}
+
+	r, err := NewReader(strings.NewReader("<results></results>"), FormatXML)
+	assert.Nil(t, err)
+	assert.Nil(t, r.Fields())
+	assert.Nil(t, readAll(t, r))
+}
+
+func TestOpen_XLSX(t *testing.T) {
+	path := writeXLSX(t,
+		`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="inlineStr"><is><t>title</t></is></c></row>
+<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2"><v>80</v></c><c r="C2" t="inlineStr"><is><r><t>a</t></r><r><t>b</t></r></is></c></row>
+<row r="3"/>
+<row r="4"><c r="A4" t="str"><v>1.1.1.2</v></c></row>`,
+		`<si><t>ip</t></si><si><t>port</t><rPh><t>x</t></rPh></si><si><t>1.1.1.1</t></si>`)
+
+	format, err := DetectFormat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, FormatXLSX, format)
+
+	r, err := Open(path, "")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ip", "port", "title"}, r.Fields())
+	assert.Equal(t, [][]string{{"1.1.1.1", "80", "ab"}, {"1.1.1.2", "", ""}}, readAll(t, r))
+
+	_, err = NewReader(strings.NewReader(""), FormatXLSX)
+	assert.Error(t, err)
+}
+
+func TestIterate(t *testing.T) {
+	path := writeFile(t, "a.csv", "ip,port\n1.1.1.1,80\n1.1.1.2\n1.1.1.3,443,x\n1.1.1.4,80\n1.1.1.5,80,y\n")
+	rows, err := Iterate(path, "")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ip", "port"}, rows.Fields())
+	// rows with extra values are skipped and reported
+	var skipped []int
+	rows.OnSkip = func(err *RowError) {
+		skipped = append(skipped, err.Line)
+	}
+	var data []CSVRow
+	for rows.Next() {
+		data = append(data, rows.Row())
+	}
+	assert.Equal(t, []CSVRow{{"ip": "1.1.1.1", "port": "80"}, {"ip": "1.1.1.2", "port": ""}, {"ip": "1.1.1.4", "port": "80"}}, data)
+	assert.Equal(t, 3, rows.Count())
+	assert.Equal(t, 2, rows.Skipped())
+	assert.Equal(t, []int{4, 6}, skipped)
+	assert.Nil(t, rows.Err())
+	assert.False(t, rows.Next())
+	assert.Nil(t, rows.Close())
+
+	// empty file
+	rows, err = Iterate(writeFile(t, "a.csv", ""), "")
+	assert.Nil(t, err)
+	assert.False(t, rows.Next())
+	assert.Nil(t, rows.Err())
+	assert.Nil(t, rows.Fields())
+	assert.Nil(t, rows.Close())
+
+	_, err = Iterate(filepath.Join(t.TempDir(), "none.csv"), "")
+	assert.Error(t, err)
+}
+
+func TestLoadFile(t *testing.T) {
+	rows, fields, err := LoadFile(writeFile(t, "a.txt", "ip,port\n1.1.1.1,80\n"), "")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ip", "port"}, fields)
+	assert.Equal(t, []CSVRow{{"ip": "1.1.1.1", "port": "80"}}, rows)
+
+	_, _, err = LoadFile(writeFile(t, "a.csv", ""), "yaml")
+	assert.Error(t, err)
+}
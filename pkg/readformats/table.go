@@ -0,0 +1,163 @@
+package readformats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// rowReader reads raw rows of a table, such as csv and xlsx
+type rowReader interface {
+	Read() ([]string, error)
+	Line() int // line number of the last row read
+}
+
+// csvRows rows of csv, fields per record is not checked so that ragged rows are tolerated
+type csvRows struct {
+	*csv.Reader
+}
+
+func (r csvRows) Line() int {
+	line, _ := r.FieldPos(0)
+	return line
+}
+
+func newCSVRows(r io.Reader, comma rune) rowReader {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1 // title lines of web export and ragged rows
+	reader.LazyQuotes = true
+	return csvRows{reader}
+}
+
+// row raw row with its line number
+type row struct {
+	values []string
+	line   int
+}
+
+// maxHeaderLines max lines before the header row of web export
+const maxHeaderLines = 10
+
+// tableReader reader of table which has a header row.
+// Header of fofa web export may be after title lines, and columns are named by display names,
+// which are mapped to fofa fields.
+type tableReader struct {
+	rows    rowReader
+	closer  io.Closer
+	fields  []string
+	line    int   // line number of the last row read
+	pending []row // rows read ahead when finding the header
+}
+
+// findHeader read ahead rows to find the header of web export, nil if not found
+func (r *tableReader) findHeader(first []string) ([]string, error) {
+	r.pending = append(r.pending, row{first, r.rows.Line()})
+	for len(r.pending) <= maxHeaderLines {
+		last := r.pending[len(r.pending)-1]
+		if knownColumns(last.values) >= 2 {
+			r.pending = nil
+			return last.values, nil
+		}
+		next, err := r.rows.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		r.pending = append(r.pending, row{next, r.rows.Line()})
+	}
+	return nil, nil
+}
+
+func (r *tableReader) Fields() []string {
+	return r.fields
+}
+
+// Line line number of the last row read, row number of xlsx
+func (r *tableReader) Line() int {
+	return r.line
+}
+
+func (r *tableReader) next() ([]string, error) {
+	if len(r.pending) > 0 {
+		next := r.pending[0]
+		r.pending = r.pending[1:]
+		r.line = next.line
+		return next.values, nil
+	}
+	values, err := r.rows.Read()
+	if err != nil {
+		return nil, err
+	}
+	r.line = r.rows.Line()
+	return values, nil
+}
+
+// Read next row. Rows are ragged tolerated: short rows are filled with empty values,
+// like trailing empty cells of xlsx, and extra empty values are dropped, like trailing delimiters.
+// Extra non-empty values are reported as RowError with the line number, as they can't be aligned with the header,
+// the next row can still be read.
+func (r *tableReader) Read() ([]string, error) {
+	values, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) > len(r.fields) {
+		for _, v := range values[len(r.fields):] {
+			if len(v) > 0 {
+				return nil, &RowError{
+					Line: r.line,
+					Err:  fmt.Errorf("row has %d fields, more than %d of header", len(values), len(r.fields)),
+				}
+			}
+		}
+		values = values[:len(r.fields)]
+	}
+	for len(values) < len(r.fields) {
+		values = append(values, "")
+	}
+	return values, nil
+}
+
+func (r *tableReader) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
+
+// newTableReader reader of rows, the first row is the header.
+// If webExport, or the first row looks like a title line or display names of fofa web export,
+// the header is the first row which has fofa fields in the beginning lines, and columns are mapped to fofa fields.
+func newTableReader(rows rowReader, closer io.Closer, webExport bool) (*tableReader, error) {
+	r := &tableReader{rows: rows, closer: closer}
+	first, err := rows.Read()
+	if err == io.EOF {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.line = rows.Line()
+
+	r.fields = first
+	if webExport || isTitleRow(first) || isWebExportHeader(first) {
+		header, err := r.findHeader(first)
+		if err != nil {
+			return nil, err
+		}
+		if header != nil {
+			r.fields = WebExportFields(header)
+		} else {
+			// not found, the first row is header
+			r.pending = r.pending[1:]
+		}
+	}
+	for i, field := range r.fields {
+		r.fields[i] = strings.TrimSpace(field)
+	}
+	return r, nil
+}
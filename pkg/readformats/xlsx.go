@@ -0,0 +1,302 @@
+package readformats
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// isXLSX file is xlsx by extension or content
+func isXLSX(filePath string) bool {
+	if strings.EqualFold(filepath.Ext(filePath), ".xlsx") {
+		return true
+	}
+	format, _ := DetectFormat(filePath)
+	return format == FormatXLSX
+}
+
+// xlsxRows reads rows of the first sheet of xlsx, cells are read as text
+type xlsxRows struct {
+	zr     *zip.ReadCloser
+	sheet  io.ReadCloser
+	d      *xml.Decoder
+	shared []string // shared strings
+	line   int      // row number of the last row read
+}
+
+// xlsxText text of rich text element like <si> and <is>, phonetic runs are ignored
+func xlsxText(d *xml.Decoder, start xml.StartElement) (string, error) {
+	var b strings.Builder
+	depth := 1
+	for depth > 0 {
+		t, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				var s string
+				if err = d.DecodeElement(&s, &t); err != nil {
+					return "", err
+				}
+				b.WriteString(s)
+			case "rPh":
+				if err = d.Skip(); err != nil {
+					return "", err
+				}
+			default:
+				depth++
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return b.String(), nil
+}
+
+// openZipFile open file in zip by name
+func openZipFile(zr *zip.ReadCloser, name string) (io.ReadCloser, error) {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f.Open()
+		}
+	}
+	return nil, fmt.Errorf("%s not found", name)
+}
+
+// loadSharedStrings strings of xl/sharedStrings.xml, nil if not exists
+func loadSharedStrings(zr *zip.ReadCloser) ([]string, error) {
+	f, err := openZipFile(zr, "xl/sharedStrings.xml")
+	if err != nil {
+		return nil, nil
+	}
+	defer f.Close()
+
+	var shared []string
+	d := xml.NewDecoder(f)
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return shared, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := t.(xml.StartElement); ok && start.Name.Local == "si" {
+			s, err := xlsxText(d, start)
+			if err != nil {
+				return nil, err
+			}
+			shared = append(shared, s)
+		}
+	}
+}
+
+// firstSheet path of the first sheet in workbook
+func firstSheet(zr *zip.ReadCloser) string {
+	const defaultSheet = "xl/worksheets/sheet1.xml"
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	for name, v := range map[string]interface{}{"xl/workbook.xml": &workbook, "xl/_rels/workbook.xml.rels": &rels} {
+		f, err := openZipFile(zr, name)
+		if err != nil {
+			return defaultSheet
+		}
+		err = xml.NewDecoder(f).Decode(v)
+		f.Close()
+		if err != nil {
+			return defaultSheet
+		}
+	}
+	if len(workbook.Sheets) == 0 {
+		return defaultSheet
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID == workbook.Sheets[0].ID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/")
+			}
+			return path.Join("xl", rel.Target)
+		}
+	}
+	return defaultSheet
+}
+
+// columnIndex index of column of cell reference like B2, -1 if invalid
+func columnIndex(ref string) int {
+	index := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A') + 1
+		n++
+	}
+	if n == 0 {
+		return -1
+	}
+	return index - 1
+}
+
+// cell value of <c> element
+func (r *xlsxRows) cell(start xml.StartElement) (string, error) {
+	var cellType string
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "t" {
+			cellType = attr.Value
+		}
+	}
+
+	var value string
+	for {
+		t, err := r.d.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "v":
+				if err = r.d.DecodeElement(&value, &t); err != nil {
+					return "", err
+				}
+			case "is":
+				if value, err = xlsxText(r.d, t); err != nil {
+					return "", err
+				}
+			default:
+				if err = r.d.Skip(); err != nil {
+					return "", err
+				}
+			}
+		case xml.EndElement:
+			switch cellType {
+			case "s":
+				i, err := strconv.Atoi(value)
+				if err != nil || i < 0 || i >= len(r.shared) {
+					return "", fmt.Errorf("invalid shared string index: %s", value)
+				}
+				return r.shared[i], nil
+			case "b":
+				return strconv.FormatBool(value == "1"), nil
+			}
+			return value, nil
+		}
+	}
+}
+
+// Read next row, empty rows are skipped
+func (r *xlsxRows) Read() ([]string, error) {
+	for {
+		t, err := r.d.Token()
+		if err != nil {
+			return nil, err
+		}
+		start, ok := t.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		r.line++
+		for _, attr := range start.Attr {
+			if attr.Name.Local == "r" {
+				if n, err := strconv.Atoi(attr.Value); err == nil {
+					r.line = n
+				}
+			}
+		}
+
+		var row []string
+		for {
+			t, err = r.d.Token()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := t.(xml.EndElement); ok {
+				break
+			}
+			start, ok := t.(xml.StartElement)
+			if !ok {
+				continue
+			}
+			if start.Name.Local != "c" {
+				if err = r.d.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			index := len(row)
+			for _, attr := range start.Attr {
+				if attr.Name.Local == "r" {
+					if i := columnIndex(attr.Value); i >= index {
+						index = i
+					}
+				}
+			}
+			value, err := r.cell(start)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", r.line, err)
+			}
+			for len(row) < index {
+				// empty cells are omitted
+				row = append(row, "")
+			}
+			row = append(row, value)
+		}
+		if len(row) > 0 {
+			return row, nil
+		}
+	}
+}
+
+// Line row number of the last row read
+func (r *xlsxRows) Line() int {
+	return r.line
+}
+
+// Close the sheet and file
+func (r *xlsxRows) Close() error {
+	r.sheet.Close()
+	return r.zr.Close()
+}
+
+// openXLSX open rows of the first sheet of xlsx file
+func openXLSX(filePath string) (*xlsxRows, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := loadSharedStrings(zr)
+	if err != nil {
+		zr.Close()
+		return nil, err
+	}
+	sheet, err := openZipFile(zr, firstSheet(zr))
+	if err != nil {
+		zr.Close()
+		return nil, err
+	}
+	return &xlsxRows{
+		zr:     zr,
+		sheet:  sheet,
+		d:      xml.NewDecoder(sheet),
+		shared: shared,
+	}, nil
+}
@@ -0,0 +1,97 @@
+package readformats
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+const xmlRecordName = "result"
+
+// xmlReader reader of xml document written by outformats.XMLWriter, both elements and attributes mode,
+// fields are escaped xml names of the first result element
+type xmlReader struct {
+	d       *xml.Decoder
+	fields  []string
+	pending []string // values of the first result
+}
+
+// nextResult names and values of next result element, io.EOF if no more
+func (r *xmlReader) nextResult() ([]string, map[string]string, error) {
+	for {
+		t, err := r.d.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		start, ok := t.(xml.StartElement)
+		if !ok || start.Name.Local != xmlRecordName {
+			continue
+		}
+
+		var names []string
+		values := make(map[string]string)
+		for _, attr := range start.Attr {
+			names = append(names, attr.Name.Local)
+			values[attr.Name.Local] = attr.Value
+		}
+		for {
+			t, err = r.d.Token()
+			if err != nil {
+				return nil, nil, fmt.Errorf("parse xml result failed: %w", err)
+			}
+			switch t := t.(type) {
+			case xml.StartElement:
+				var value string
+				if err = r.d.DecodeElement(&value, &t); err != nil {
+					return nil, nil, fmt.Errorf("parse xml element %s failed: %w", t.Name.Local, err)
+				}
+				names = append(names, t.Name.Local)
+				values[t.Name.Local] = value
+			case xml.EndElement:
+				return names, values, nil
+			}
+		}
+	}
+}
+
+func (r *xmlReader) Fields() []string {
+	return r.fields
+}
+
+// Read next result element
+func (r *xmlReader) Read() ([]string, error) {
+	if r.pending != nil {
+		record := r.pending
+		r.pending = nil
+		return record, nil
+	}
+	_, values, err := r.nextResult()
+	if err != nil {
+		return nil, err
+	}
+	record := make([]string, len(r.fields))
+	for i, field := range r.fields {
+		record[i] = values[field]
+	}
+	return record, nil
+}
+
+func (r *xmlReader) Close() error {
+	return nil
+}
+
+func newXMLReader(in io.Reader) (*xmlReader, error) {
+	r := &xmlReader{d: xml.NewDecoder(in)}
+	names, values, err := r.nextResult()
+	if err == io.EOF {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.fields = names
+	for _, name := range names {
+		r.pending = append(r.pending, values[name])
+	}
+	return r, nil
+}